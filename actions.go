@@ -0,0 +1,23 @@
+package clix
+
+// UsageExitCode is the exit code of the errors returned by the actions of the adapters, such as clixv3.Action,
+// when the config does not parse
+const UsageExitCode = 2
+
+// UsageError is returned by the actions of the adapters when the config could not be parsed or validated.
+// It implements the ExitCoder interface of both urfave v2 and v3, so the application exits with UsageExitCode.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return "Incorrect Usage: " + e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UsageError) ExitCode() int {
+	return UsageExitCode
+}
@@ -0,0 +1,421 @@
+package clix_test
+
+import (
+	"context"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/modfin/clix"
+	"github.com/modfin/clix/clixv3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cli "github.com/urfave/cli/v3"
+)
+
+// The tests of this file parse the flags of clixv3.Flags from argv, the round trip the other tests can not make
+// without importing clixv3.
+
+// fixedClock is the clock of the times tests, a Sunday afternoon
+func fixedClock() time.Time { return time.Date(2024, 3, 10, 14, 30, 0, 0, time.UTC) }
+
+func render(t *testing.T, tmpl *template.Template, data any) string {
+	var b strings.Builder
+	require.NoError(t, tmpl.Execute(&b, data))
+	return b.String()
+}
+
+func TestFlagsV3RoundTrip(t *testing.T) {
+	flags, err := clixv3.Flags[clix.FlagsConfig]()
+	require.NoError(t, err)
+	t.Setenv("FLAGS_TEST_NAME", "from-env")
+
+	var config clix.FlagsConfig
+	cmd := &cli.Command{
+		Name:  "test",
+		Flags: flags,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			config = clix.ParseCommand[clix.FlagsConfig](cmd)
+			return nil
+		},
+	}
+	err = cmd.Run(context.Background(), []string{"test",
+		"--big", "9223372036854775807",
+		"--small", "-3",
+		"--start", "2023-01-02T15:04:05Z",
+		"--weights", "1.5",
+		"--labels", "a=b",
+		"--db-name", "users",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "from-env", config.Name)
+	assert.Equal(t, 8080, config.Port)
+	assert.Equal(t, int64(9223372036854775807), config.Big)
+	assert.Equal(t, int32(-3), config.Small)
+	assert.Equal(t, 0.5, config.Ratio)
+	assert.Equal(t, 5*time.Second, config.Timeout)
+	assert.Equal(t, time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC), config.Start)
+	assert.Equal(t, []string{"a", "b"}, config.Tags)
+	assert.Equal(t, []float64{1.5}, config.Weights)
+	assert.Equal(t, map[string]string{"a": "b"}, config.Labels)
+	assert.Equal(t, "localhost", config.Database.Host)
+	assert.Equal(t, "users", config.Database.Name)
+	assert.Equal(t, "from-env", config.Again.Name)
+}
+
+func TestFlagsV3Compiled(t *testing.T) {
+	flags, err := clixv3.Flags[clix.Router]()
+	require.NoError(t, err)
+	assert.Equal(t, &cli.StringFlag{Name: "route", Value: "^/api/"}, flags[0])
+	assert.Equal(t, &cli.StringSliceFlag{Name: "ignore"}, flags[1])
+
+	var cfg clix.Router
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg, err = clix.TryParseCommand[clix.Router](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"router",
+		"--route", "^/v2/", "--ignore", `\.tmp$`, "--notify", "{{.}} is down",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "^/v2/", cfg.Route.String())
+	assert.Equal(t, `\.tmp$`, cfg.Ignore[0].String())
+	assert.Equal(t, "db is down", render(t, cfg.Notify, "db"))
+}
+
+func TestCompiledToArgsAndDiff(t *testing.T) {
+	cfg := clix.Defaults[clix.Router]()
+	cfg.Ignore = []*regexp.Regexp{regexp.MustCompile(`\.tmp$`)}
+	cfg.Notify = template.Must(template.New("").Parse(`{{ .Host }} {{- template "s" .}}{{define "s"}}!{{end}}`))
+
+	args, err := clix.ToArgs(cfg)
+	require.NoError(t, err)
+	// the default route is left out, templates clix did not parse are written back with their actions normalized
+	assert.Equal(t, []string{`--ignore=\.tmp$`, `--notify={{.Host}}{{template "s" .}}{{define "s"}}!{{end}}`}, args)
+
+	flags, err := clixv3.Flags[clix.Router]()
+	require.NoError(t, err)
+	var parsed clix.Router
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			parsed, err = clix.TryParseCommand[clix.Router](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"router"}, args...)))
+	require.NoError(t, err)
+	assert.Equal(t, "db!", render(t, parsed.Notify, map[string]string{"Host": "db"}))
+	assert.Empty(t, clix.Diff(cfg, parsed))
+
+	parsed.Route = regexp.MustCompile("^/v2/")
+	changes := clix.Diff(cfg, parsed)
+	require.Len(t, changes, 1)
+	assert.Equal(t, clix.Change{Flag: "route", Field: "Route", Old: "^/api/", New: "^/v2/"}, changes[0])
+}
+
+func TestFlagsV3List(t *testing.T) {
+	flags, err := clixv3.Flags[clix.Proxy]()
+	require.NoError(t, err)
+	var usage string
+	for _, f := range flags {
+		if sf, ok := f.(*cli.StringFlag); ok && sf.Name == "upstream" {
+			usage = sf.Usage
+		}
+	}
+	assert.Equal(t, "backends to proxy to (JSON or YAML array)", usage)
+
+	var cfg clix.Proxy
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg, err = clix.TryParseCommand[clix.Proxy](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"proxy", "--upstream", `[{"host": "a"}]`}))
+	require.NoError(t, err)
+	assert.Equal(t, []clix.Upstream{{Host: "a", Port: 80}}, cfg.Upstreams)
+}
+
+func TestFlagsV3Map(t *testing.T) {
+	flags, err := clixv3.Flags[clix.MultiTenant]()
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, &cli.StringSliceFlag{Name: "tenants", Usage: "tenants to serve"}, flags[0])
+}
+
+func TestFlagsV3MapDefault(t *testing.T) {
+	type Config struct {
+		Tenants map[string]clix.Tenant `cli-prefix:"tenant-" cli-keys:"tenants" cli-default:"acme,globex"`
+	}
+	flags, err := clixv3.Flags[Config]()
+	require.NoError(t, err)
+	var names []string
+	for _, f := range flags {
+		names = append(names, f.Names()[0])
+	}
+	assert.Equal(t, []string{
+		"tenants",
+		"tenant-acme-host", "tenant-acme-db-host", "tenant-acme-db-port",
+		"tenant-globex-host", "tenant-globex-db-host", "tenant-globex-db-port",
+	}, names)
+
+	var cfg Config
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg, err = clix.TryParseCommand[Config](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"serve",
+		"--tenant-acme-db-host", "db.acme", "--tenant-globex-db-host", "db.globex", "--tenant-globex-db-port", "5433",
+	}))
+	require.NoError(t, err)
+	require.Len(t, cfg.Tenants, 2)
+	assert.Equal(t, "db.acme", cfg.Tenants["acme"].DB.Host)
+	assert.Equal(t, 5432, cfg.Tenants["acme"].DB.Port)
+	assert.Equal(t, 5433, cfg.Tenants["globex"].DB.Port)
+
+	// the instances a reader names replace the default ones
+	r, err := clix.NewFileReader([]byte("tenant-initech-db-host: db.initech\n"))
+	require.NoError(t, err)
+	cfg, err = clix.TryParse[Config](r)
+	require.NoError(t, err)
+	require.Len(t, cfg.Tenants, 1)
+	assert.Equal(t, "db.initech", cfg.Tenants["initech"].DB.Host)
+}
+
+func TestToArgs(t *testing.T) {
+	cfg := clix.Defaults[clix.Worker]()
+	cfg.Name = "w1"
+	cfg.Cache = false
+	cfg.Timeout = time.Minute
+	cfg.Tags = []string{"a", "b"}
+	cfg.Labels = map[string]string{"team": "core", "env": "prod"}
+	cfg.Limits.MaxBody = 512 << 20
+	cfg.Limits.Bursts = []clix.Rate{{10, time.Second}}
+	cfg.Src = "-"
+	cfg.Files = []string{"x"}
+
+	args, err := clix.ToArgs(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--name=w1", "--cache=false", "--timeout=1m0s", "--tags=a", "--tags=b", "--labels=env=prod", "--labels=team=core",
+		"--limits-max-body=512MiB", "--limits-bursts=10/s",
+		"--", "-", "x",
+	}, args)
+
+	flags, err := clixv3.Flags[clix.Worker]()
+	require.NoError(t, err)
+	var parsed clix.Worker
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			parsed, err = clix.TryParseCommand[clix.Worker](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"worker"}, args...)))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, parsed)
+}
+
+func TestFlagsV3Network(t *testing.T) {
+	flags, err := clixv3.Flags[clix.Gateway]()
+	require.NoError(t, err)
+	assert.Equal(t, &cli.StringFlag{Name: "listen", Value: ":8080"}, flags[0])
+
+	var cfg clix.Gateway
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg, err = clix.TryParseCommand[clix.Gateway](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"gateway",
+		"--database", "[fd00::1]", "--upstream", "http://localhost:3000",
+		"--allow", "192.168.0.0/16", "--allow", "10.0.0.0/8", "--peers", "node-1", "--peers", "node-2:80",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, clix.HostPort{Port: 8080}, cfg.Listen)
+	assert.Equal(t, clix.HostPort{Host: "fd00::1", Port: 5432}, cfg.Database)
+	assert.Equal(t, []clix.HostPort{{Host: "node-1", Port: 7946}, {Host: "node-2", Port: 80}}, cfg.Peers)
+	assert.Equal(t, "http://localhost:3000", cfg.Upstream.String())
+	assert.Len(t, cfg.Allow, 2)
+	assert.Empty(t, cfg.Mirrors)
+}
+
+func TestFlagsV3Names(t *testing.T) {
+	flags, err := clixv3.Flags[clix.RenamedConfig]()
+	require.NoError(t, err)
+	require.Len(t, flags, 5)
+	assert.Equal(t, []string{"listen-addr", "addr"}, flags[0].Names())
+	assert.True(t, flags[1].(*cli.StringFlag).Hidden)
+	assert.Equal(t, "deprecated, use --listen-addr", flags[1].(*cli.StringFlag).Usage)
+
+	run := func(args ...string) (clix.RenamedConfig, error) {
+		var cfg clix.RenamedConfig
+		flags, _ := clixv3.Flags[clix.RenamedConfig]()
+		cmd := &cli.Command{
+			Name:  "tool",
+			Flags: flags,
+			Action: func(_ context.Context, cmd *cli.Command) error {
+				var err error
+				cfg, err = clix.TryParseCommand[clix.RenamedConfig](cmd, clix.WithLogger(slog.New(slog.DiscardHandler)))
+				return err
+			},
+		}
+		err := cmd.Run(context.Background(), append([]string{"tool"}, args...))
+		return cfg, err
+	}
+
+	cfg, err := run("--addr", ":1", "--db-port-number", "5432")
+	require.NoError(t, err)
+	assert.Equal(t, ":1", cfg.Addr)
+	assert.Equal(t, 5432, cfg.DB.Port)
+
+	_, err = run("--bind", ":1", "--listen-addr", ":2")
+	assert.EqualError(t, err, "--listen-addr: --bind conflicts with --listen-addr")
+}
+
+func TestFlagsV3ExtendedTime(t *testing.T) {
+	opts := []clix.Option{clix.WithExtendedTime(), clix.WithClock(fixedClock)}
+	flags, err := clixv3.Flags[clix.Retention](opts...)
+	require.NoError(t, err)
+	assert.Equal(t, &cli.StringFlag{Name: "keep", Value: "7d"}, flags[0])
+	assert.Equal(t, &cli.StringFlag{Name: "since"}, flags[1])
+
+	var cfg clix.Retention
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg, err = clix.TryParseCommand[clix.Retention](cmd, opts...)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"prune", "--since", "yesterday 08:00", "--day", "today"}))
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, cfg.Keep)
+	assert.Equal(t, time.Date(2024, 3, 9, 8, 0, 0, 0, time.UTC), cfg.Since)
+	assert.Nil(t, cfg.Until)
+	assert.Equal(t, time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC), cfg.Day.UTC())
+}
+
+func TestFlagsV3Layouts(t *testing.T) {
+	flags, err := clixv3.Flags[clix.Retention]()
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, flags[0].(*cli.DurationFlag).Value)
+	day := flags[3].(*cli.TimestampFlag)
+	assert.Equal(t, []string{time.DateOnly, "2006-01-02 15:04"}, day.Config.Layouts)
+	assert.Equal(t, "Europe/Stockholm", day.Config.Timezone.String())
+
+	var cfg clix.Retention
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg, err = clix.TryParseCommand[clix.Retention](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"prune", "--keep", "36h", "--day", "2024-03-01 12:00"}))
+	require.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, cfg.Keep)
+	assert.Equal(t, time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), cfg.Day.UTC())
+}
+
+func TestFlagsV3Union(t *testing.T) {
+	flags, err := clixv3.Flags[clix.Blobs]()
+	require.NoError(t, err)
+	var names []string
+	for _, f := range flags {
+		names = append(names, f.Names()[0])
+	}
+	assert.Equal(t, []string{
+		"storage", "s3-bucket", "s3-region", "fs-root",
+		"backup", "backup-s3-bucket", "backup-s3-region", "backup-fs-root",
+	}, names)
+	assert.Equal(t, "where blobs are stored (s3, fs)", flags[0].(*cli.StringFlag).Usage)
+
+	var cfg clix.Blobs
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg, err = clix.TryParseCommand[clix.Blobs](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"blobs", "--storage", "s3", "--s3-bucket", "b"}))
+	require.NoError(t, err)
+	assert.Equal(t, clix.S3Storage{Bucket: "b", Region: "eu-north-1"}, cfg.Storage)
+	assert.Equal(t, &clix.FSStorage{}, cfg.Backup)
+}
+
+func TestFlagsV3ValueTypes(t *testing.T) {
+	flags, err := clixv3.Flags[clix.Limits]()
+	require.NoError(t, err)
+	assert.Equal(t, &cli.StringFlag{Name: "max-body", Value: "10MiB"}, flags[0])
+	assert.Equal(t, &cli.StringSliceFlag{Name: "chunks", Value: []string{"4KiB", "1MiB"}}, flags[5])
+
+	var cfg clix.Limits
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg, err = clix.TryParseCommand[clix.Limits](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"limits", "--disk", "1.5GB", "--bursts", "1/s", "--bursts", "2/s"}))
+	require.NoError(t, err)
+	assert.Equal(t, clix.Limits{
+		MaxBody:   10 << 20,
+		Disk:      1500000000,
+		Threshold: 85,
+		Rate:      clix.Rate{100, time.Second},
+		Bursts:    []clix.Rate{{1, time.Second}, {2, time.Second}},
+		Chunks:    []clix.ByteSize{4 << 10, 1 << 20},
+	}, cfg)
+}
+
+func TestNetworkToArgsRoundTrip(t *testing.T) {
+	upstream, err := url.Parse("https://api.example.com/v1?x=1")
+	require.NoError(t, err)
+	cfg := clix.Gateway{
+		Listen:   clix.HostPort{Port: 8080},
+		Database: clix.HostPort{Host: "db.local", Port: 5432},
+		Upstream: upstream,
+		Bind:     netip.MustParseAddr("10.0.0.1"),
+		Allow:    []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}
+	args, err := clix.ToArgs(cfg)
+	require.NoError(t, err)
+
+	flags, err := clixv3.Flags[clix.Gateway]()
+	require.NoError(t, err)
+	var parsed clix.Gateway
+	cmd := &cli.Command{
+		Flags: flags,
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			parsed, err = clix.TryParseCommand[clix.Gateway](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"gateway"}, args...)))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Listen, parsed.Listen)
+	assert.Equal(t, cfg.Database, parsed.Database)
+	assert.Equal(t, cfg.Upstream, parsed.Upstream)
+	assert.Equal(t, cfg.Bind, parsed.Bind)
+	assert.Equal(t, cfg.Allow, parsed.Allow)
+}
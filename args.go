@@ -0,0 +1,213 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// argsSlicer is the Args type of cli.Context and cli.Command, v2 and v3 have one each
+type argsSlicer interface {
+	Slice() []string
+}
+
+// readArgs returns the positional arguments of the reader, nil when it has none or can not tell.
+// The Args methods of cli.Context and cli.Command return the Args type of their urfave version, they are
+// called through reflection so that clix depends on neither.
+func readArgs(c any) []string {
+	if r, ok := c.(ArgsReader); ok {
+		return r.Args()
+	}
+	if c == nil {
+		return nil
+	}
+	m := reflect.ValueOf(c).MethodByName("Args")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	if a, ok := m.Call(nil)[0].Interface().(argsSlicer); ok {
+		return a.Slice()
+	}
+	return nil
+}
+
+// describeArg returns the argument of a field tagged `cli-arg` or `cli-args`, a tag that does not parse is left to checkTags
+func describeArg(fieldType reflect.StructField) (ArgSpec, bool) {
+	a := ArgSpec{
+		Name:     joinWords(fieldType.Name, "-"),
+		GoType:   fieldType.Type,
+		Type:     typeName(fieldType.Type),
+		Default:  fieldType.Tag.Get("cli-default"),
+		Usage:    fieldType.Tag.Get("cli-usage"),
+		Enum:     splitList(fieldType.Tag.Get("cli-oneof")),
+		Required: fieldType.Tag.Get("cli-required") == "true",
+		Min:      fieldType.Tag.Get("cli-min"),
+		Max:      fieldType.Tag.Get("cli-max"),
+		Layouts:  splitLayouts(fieldType.Tag.Get("cli-layout")),
+		TZ:       fieldType.Tag.Get("cli-tz"),
+		Path:     splitList(fieldType.Tag.Get("cli-path")),
+		Schemes:  splitList(fieldType.Tag.Get("cli-scheme")),
+		Port:     fieldType.Tag.Get("cli-port"),
+	}
+	if tag, ok := fieldType.Tag.Lookup("cli-arg"); ok {
+		parts := splitList(tag)
+		if len(parts) == 0 {
+			return a, false
+		}
+		pos, err := strconv.Atoi(parts[0])
+		if err != nil || pos < 0 {
+			return a, false
+		}
+		a.Position = pos
+		if len(parts) > 1 {
+			a.Name = parts[1]
+		}
+		return a, true
+	}
+	if tag, ok := fieldType.Tag.Lookup("cli-args"); ok && tag == "rest" && fieldType.Type.Kind() == reflect.Slice {
+		a.Position = -1
+		a.Type = typeName(fieldType.Type.Elem())
+		return a, true
+	}
+	return a, false
+}
+
+// checkArgTag reports a `cli-arg` or `cli-args` tag that describeArg does not accept
+func checkArgTag(fieldType reflect.StructField) error {
+	if tag, ok := fieldType.Tag.Lookup("cli-arg"); ok {
+		if _, ok := describeArg(fieldType); !ok {
+			return fmt.Errorf("invalid cli-arg %q, expected a position and an optional name", tag)
+		}
+	}
+	if tag, ok := fieldType.Tag.Lookup("cli-args"); ok {
+		switch {
+		case tag != "rest":
+			return fmt.Errorf("invalid cli-args %q, expected \"rest\"", tag)
+		case fieldType.Type.Kind() != reflect.Slice:
+			return fmt.Errorf("cli-args on a field that is not a slice")
+		}
+	}
+	return nil
+}
+
+// flagSpec returns the argument as a flag, to share the checks and rules of flags.
+// The default of the remaining arguments is a comma separated list, as for slice flags.
+func (a ArgSpec) flagSpec() FlagSpec {
+	return FlagSpec{Name: a.Name, Field: a.Field, GoType: a.GoType, Default: a.Default, Enum: a.Enum, Required: a.Required, Min: a.Min, Max: a.Max, Layouts: a.Layouts, TZ: a.TZ, Path: a.Path, Schemes: a.Schemes, Port: a.Port}
+}
+
+// values returns the arguments the field is read from, and whether any was given.
+// The remaining arguments start after the last positional one, at rest.
+func (a ArgSpec) values(args []string, rest int) ([]string, bool) {
+	if a.Position >= 0 {
+		if a.Position < len(args) {
+			return args[a.Position : a.Position+1], true
+		}
+		return nil, false
+	}
+	if rest < len(args) {
+		return args[rest:], true
+	}
+	return nil, false
+}
+
+// checkArgs reports positions used by several fields, positions no field is bound to and several `cli-args` fields.
+// It returns the index of the first remaining argument.
+func checkArgs(args []ArgSpec, errs *ParseError) int {
+	byPos := map[int]string{}
+	max, rest := -1, ""
+	for _, a := range args {
+		if a.Position < 0 {
+			if rest != "" {
+				errs.Errors = append(errs.Errors, &FieldError{Arg: a.Name, Field: a.Field, Err: fmt.Errorf("remaining arguments already bound to %s", rest)})
+			}
+			rest = a.Field
+			continue
+		}
+		if other, ok := byPos[a.Position]; ok {
+			errs.Errors = append(errs.Errors, &FieldError{Arg: a.Name, Field: a.Field, Err: fmt.Errorf("argument %d already bound to %s", a.Position, other)})
+			continue
+		}
+		byPos[a.Position] = a.Field
+		if a.Position > max {
+			max = a.Position
+		}
+	}
+	for i := 0; i < max; i++ {
+		if _, ok := byPos[i]; !ok {
+			errs.add("", "", fmt.Errorf("no field bound to argument %d", i))
+		}
+	}
+	return max + 1
+}
+
+// assignArg sets the field of a from the arguments, or from its `cli-default` when it was not given.
+// Flags carry the defaults of flag fields, arguments have no flag so they get them here.
+func assignArg(a ArgSpec, val reflect.Value, args []string, rest int, c ContextReader, o *options) *FieldError {
+	field := fieldByIndexAlloc(val, a.Index)
+	texts, given := a.values(args, rest)
+	if !given {
+		field.SetZero()
+		if a.Default != "" {
+			if def, err := parseTagValue(a.flagSpec(), a.Default); err == nil {
+				field.Set(def)
+			}
+		}
+		return nil
+	}
+
+	v, err := a.parse(texts, o)
+	if err != nil {
+		field.SetZero()
+		return &FieldError{Arg: a.Name, Field: a.Field, Err: err}
+	}
+	field.Set(v)
+	if err := finishValue(a.flagSpec(), field, c, o); err != nil {
+		return &FieldError{Arg: a.Name, Field: a.Field, Err: err}
+	}
+	return nil
+}
+
+// parse converts the given arguments to the type of the field, the remaining arguments element by element
+func (a ArgSpec) parse(texts []string, o *options) (reflect.Value, error) {
+	f := a.flagSpec()
+	if a.Position >= 0 {
+		return parseValue(f, texts[0], o)
+	}
+	v := reflect.MakeSlice(a.GoType, len(texts), len(texts))
+	f.GoType = a.GoType.Elem()
+	for i, text := range texts {
+		elem, err := parseValue(f, text, o)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Index(i).Set(elem)
+	}
+	return v, nil
+}
+
+// ArgsUsage returns the usage of the positional arguments of the section, e.g. "<src> <dst> [files...]".
+// Required arguments are in angle brackets, optional ones in square brackets.
+func (s SectionSpec) ArgsUsage() string {
+	var args []ArgSpec
+	s.WalkArgs(func(_ SectionSpec, a ArgSpec) { args = append(args, a) })
+	sort.SliceStable(args, func(i, j int) bool {
+		return args[j].Position < 0 && args[i].Position >= 0 || args[i].Position >= 0 && args[i].Position < args[j].Position
+	})
+
+	parts := make([]string, len(args))
+	for i, a := range args {
+		name := a.Name
+		if a.Position < 0 {
+			name += "..."
+		}
+		if a.Required {
+			parts[i] = "<" + name + ">"
+		} else {
+			parts[i] = "[" + name + "]"
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,120 @@
+package clix
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CopyArgs struct {
+	Src     string   `cli-arg:"0" cli-required:"true"`
+	Dst     string   `cli-arg:"1,dst" cli-required:"true"`
+	Files   []string `cli-args:"rest"`
+	Verbose bool     `cli:"verbose"`
+}
+
+type Resize struct {
+	Count int    `cli-arg:"0" cli-min:"1" cli-max:"10" cli-default:"3"`
+	Mode  string `cli-arg:"1" cli-oneof:"fast,slow"`
+	Sizes []int  `cli-args:"rest"`
+}
+
+// argsMock is a reader with positional arguments and no flags
+type argsMock struct {
+	*cliContextMock
+	args []string
+}
+
+func (m argsMock) Args() []string { return m.args }
+
+func withArgs(args ...string) argsMock {
+	return argsMock{newMockContext(), args}
+}
+
+func TestDescribeArgs(t *testing.T) {
+	spec := Describe[CopyArgs]()
+	require.Len(t, spec.Args, 3)
+	assert.Equal(t, ArgSpec{Name: "src", Position: 0, Field: "Src", Index: []int{0}, Type: "string", GoType: spec.Args[0].GoType, Required: true}, spec.Args[0])
+	assert.Equal(t, "dst", spec.Args[1].Name)
+	assert.Equal(t, -1, spec.Args[2].Position)
+	assert.Equal(t, "string", spec.Args[2].Type)
+	require.Len(t, spec.Flags, 1)
+	assert.Equal(t, "<src> <dst> [files...]", spec.ArgsUsage())
+	assert.Equal(t, "[count] [mode] [sizes...]", Describe[Resize]().ArgsUsage())
+}
+
+func TestParseArgs(t *testing.T) {
+	cfg, err := TryParse[CopyArgs](withArgs("a.txt", "out", "b.txt", "c.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, CopyArgs{Src: "a.txt", Dst: "out", Files: []string{"b.txt", "c.txt"}}, cfg)
+
+	r, err := TryParse[Resize](withArgs("5", "fast", "10", "20"))
+	require.NoError(t, err)
+	assert.Equal(t, Resize{Count: 5, Mode: "fast", Sizes: []int{10, 20}}, r)
+
+	r, err = TryParse[Resize](withArgs())
+	require.NoError(t, err)
+	assert.Equal(t, Resize{Count: 3}, r)
+}
+
+func TestParseArgsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{"required", []string{"a.txt"}, "<dst>: required argument is not set"},
+		{"conversion", []string{"many"}, `<count>: strconv.ParseInt: parsing "many": invalid syntax`},
+		{"bounds", []string{"11"}, "<count>: 11 is greater than 10"},
+		{"oneof", []string{"1", "medium"}, `<mode>: "medium" is not one of fast, slow`},
+		{"rest", []string{"1", "fast", "2", "x"}, `<sizes>: strconv.ParseInt: parsing "x": invalid syntax`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.name == "required" {
+				_, err = TryParse[CopyArgs](withArgs(tt.args...))
+			} else {
+				_, err = TryParse[Resize](withArgs(tt.args...))
+			}
+			assert.EqualError(t, err, tt.err)
+			var fe *FieldError
+			require.True(t, errors.As(err, &fe))
+			assert.NotEmpty(t, fe.Arg)
+		})
+	}
+}
+
+func TestCompileArgsErrors(t *testing.T) {
+	type Config struct {
+		A string   `cli-arg:"0"`
+		B string   `cli-arg:"0"`
+		C string   `cli-arg:"2"`
+		D []string `cli-args:"rest"`
+		E []string `cli-args:"rest"`
+		F string   `cli-args:"rest"`
+		G string   `cli-arg:"first"`
+	}
+	_, err := Compile[Config]()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"F: cli-args on a field that is not a slice",
+		`G: invalid cli-arg "first", expected a position and an optional name`,
+		"<b>: argument 0 already bound to A",
+		"<e>: remaining arguments already bound to D",
+		"no field bound to argument 1",
+	}, messages)
+}
+
+func TestDocsArgs(t *testing.T) {
+	md := DocsMarkdown[CopyArgs](DocsOptions{})
+	assert.Contains(t, md, "Arguments: `<src> <dst> [files...]`")
+	assert.Contains(t, md, "| `<files>...` | string |")
+}
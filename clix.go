@@ -3,27 +3,42 @@ package clix
 import (
 	"reflect"
 	"time"
+
+	"github.com/modfin/clix/clixrt"
 )
 
 // ContextReader defines the interface for reading values from a CLI context.
 // This abstraction allows for easier testing by allowing mock implementations.
-type ContextReader interface {
-	String(name string) string
-	Int(name string) int
-	Int64(name string) int64
-	Uint(name string) uint
-	Uint64(name string) uint64
-	Bool(name string) bool
-	Float64(name string) float64
-	Timestamp(name string) *time.Time
-	Duration(name string) time.Duration
-	StringSlice(name string) []string
-	IntSlice(name string) []int
-	Int64Slice(name string) []int64
-	UintSlice(name string) []uint
-	Uint64Slice(name string) []uint64
-	Float64Slice(name string) []float64
-}
+// It is declared in clixrt, the runtime of the code clixgen writes.
+type ContextReader = clixrt.ContextReader
+
+// Optional ContextReader capabilities.
+// Parse uses them when the reader implements them and falls back to the ContextReader methods otherwise.
+type (
+	// IsSetReader reports whether a flag was given, as opposed to holding its default value
+	IsSetReader interface {
+		IsSet(name string) bool
+	}
+	Int32Reader     = clixrt.Int32Reader
+	Uint32Reader    = clixrt.Uint32Reader
+	Float32Reader   = clixrt.Float32Reader
+	StringMapReader = clixrt.StringMapReader
+	// FlagNamesReader lists the flag names the reader holds, it is how the elements of a slice of structs are found.
+	// cli.Context and cli.Command implement it.
+	FlagNamesReader interface {
+		FlagNames() []string
+	}
+	// ArgsReader returns the positional arguments, the fields tagged `cli-arg` and `cli-args` are read from them.
+	// cli.Context and cli.Command are read through their Args().Slice().
+	ArgsReader interface {
+		Args() []string
+	}
+	// ConfigPathReader returns the config file the reader was read from, relative `cli-path` values
+	// are taken relative to its directory. FileReader implements it.
+	ConfigPathReader interface {
+		Path() string
+	}
+)
 
 // Parse converts CLI context into a typed configuration struct.
 // It uses reflection to map CLI flags to struct fields based on struct tags.
@@ -40,79 +55,111 @@ type ContextReader interface {
 //	}
 //
 //	cfg := clix.Parse[Config](ctx)
-func Parse[A any](c ContextReader) A {
-	var cfg A
-	AssignValueToCliFields(&cfg, "", c)
+func Parse[A any](c ContextReader, opts ...Option) A {
+	cfg, _ := parse[A](c, newOptions(opts))
 	return cfg
 }
 
+// parse populates an A, the error reports flags given under several names with conflicting values
+func parse[A any](c ContextReader, o *options) (A, error) {
+	var cfg A
+	val := reflect.ValueOf(&cfg).Elem()
+	err := planFor(val.Type(), o).assign(val, "", c, o)
+	return cfg, err
+}
+
 // ParseContext is an alias for `clix.Parse[Config](ctx) to align with the v3 function name`
-func ParseContext[A any](ctx ContextReader) A {
-	return Parse[A](ctx)
+func ParseContext[A any](ctx ContextReader, opts ...Option) A {
+	return Parse[A](ctx, opts...)
 }
 
-// AssignValueToCliFields recursively assigns values from CLI flags to struct fields.
-// It uses reflection to iterate over the struct fields and set their values based on CLI flags.
+// AssignValueToCliFields assigns values from CLI flags to struct fields, recursing into nested structs.
+// The struct type is compiled into a Plan on first use (see Compile) and the plan is cached,
+// so repeated calls only look up the flags and set the fields.
 // Parameters:
 //   - v: a pointer to the struct to populate
 //   - prefix: prefix for the CLI flags (used for nested structs)
 //   - c: the CLI context containing the flag values
-func AssignValueToCliFields(v interface{}, prefix string, c ContextReader) {
+func AssignValueToCliFields(v interface{}, prefix string, c ContextReader, opts ...Option) {
 	// Get the reflection value of the input struct
 	val := reflect.ValueOf(v).Elem()
+	o := newOptions(opts)
+	_ = planFor(val.Type(), o).assign(val, prefix, c, o)
+}
 
-	// Iterate over the struct fields
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		fieldType := val.Type().Field(i)
-
-		// Skip unexported fields (they start with lowercase)
-		if !field.CanSet() {
-			continue
-		}
+// setter sets a field from the flag with the given name, the error reports a text that does not parse
+type setter func(c ContextReader, name string, field reflect.Value) error
 
-		// Get the "cli" tag value
-		tag := fieldType.Tag.Get("cli")
+// compileSetter returns the setter for fields of type t, or nil if the type is not supported.
+// Type checks are done here, once per field, rather than every time a value is assigned.
+func compileSetter(t reflect.Type) setter {
+	// Handle time.Time and *time.Time types
+	if t == reflect.TypeOf(time.Time{}) || t == reflect.PointerTo(reflect.TypeOf(time.Time{})) {
+		return setTimeValue
+	}
 
-		// Handle nested structs without a cli tag
-		if tag == "" && field.Kind() == reflect.Struct {
-			// Get the prefix for the nested struct
-			nestedPrefix := fieldType.Tag.Get("cli-prefix")
-			// Process the nested struct recursively if it's addressable
-			if field.Addr().CanInterface() {
-				AssignValueToCliFields(field.Addr().Interface(), prefix+nestedPrefix, c)
-			}
-			continue
+	// Handle time.Duration type
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.SetInt(int64(c.Duration(name)))
+			return nil
 		}
+	}
 
-		// Process fields with cli tags
-		if tag != "" {
-			// Combine the prefix with the tag
-			fullTag := prefix + tag
+	// Handle ByteSize, Percent, Rate and other types read from their text
+	if isTextType(t) {
+		return textSetter(t)
+	}
+	if t.Kind() == reflect.Slice && isTextType(t.Elem()) {
+		return textSliceSetter(t)
+	}
 
-			// Handle time.Time and *time.Time types
-			if field.Type() == reflect.TypeOf(time.Time{}) ||
-				field.Type() == reflect.PointerTo(reflect.TypeOf(time.Time{})) {
-				setTimeValue(c, fullTag, field)
-				continue
-			}
+	// Handle other types based on their Kind
+	return fieldSetter(t)
+}
 
-			// Handle time.Duration type
-			if field.Type() == reflect.TypeOf(time.Duration(0)) {
-				field.Set(reflect.ValueOf(c.Duration(fullTag)))
-				continue
-			}
+// textSetter returns the setter of a type read from a string flag, an empty string leaves the zero value
+func textSetter(t reflect.Type) setter {
+	return func(c ContextReader, name string, field reflect.Value) error {
+		text := c.String(name)
+		if text == "" {
+			field.SetZero()
+			return nil
+		}
+		v, err := parseTextValue(t, text)
+		if err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	}
+}
 
-			// Handle other types based on their Kind
-			setFieldValue(c, fullTag, field)
+// textSliceSetter returns the setter of a slice of a text type, read from a string slice flag
+func textSliceSetter(t reflect.Type) setter {
+	return func(c ContextReader, name string, field reflect.Value) error {
+		texts := c.StringSlice(name)
+		if texts == nil {
+			field.SetZero()
+			return nil
+		}
+		list := reflect.MakeSlice(t, len(texts), len(texts))
+		for i, text := range texts {
+			v, err := parseTextValue(t.Elem(), text)
+			if err != nil {
+				return err
+			}
+			list.Index(i).Set(v)
 		}
+		field.Set(list)
+		return nil
 	}
 }
 
 // setTimeValue handles setting time.Time values from CLI flags.
 // It handles both time.Time and *time.Time types.
-func setTimeValue(c ContextReader, tag string, field reflect.Value) {
-	t := c.Timestamp(tag)
+func setTimeValue(c ContextReader, name string, field reflect.Value) error {
+	t := c.Timestamp(name)
 	if t != nil {
 		if field.Kind() == reflect.Ptr {
 			field.Set(reflect.ValueOf(t))
@@ -120,46 +167,124 @@ func setTimeValue(c ContextReader, tag string, field reflect.Value) {
 			field.Set(reflect.ValueOf(*t))
 		}
 	}
+	return nil
 }
 
-// setFieldValue sets the value of a field based on its Kind.
+// fieldSetter returns the setter of a field based on its Kind.
 // It handles primitive types and slices of primitive types.
-func setFieldValue(c ContextReader, tag string, field reflect.Value) {
-	switch field.Kind() {
+func fieldSetter(t reflect.Type) setter {
+	switch t.Kind() {
 	case reflect.String:
-		field.SetString(c.String(tag))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.SetString(c.String(name))
+			return nil
+		}
 	case reflect.Int:
-		field.SetInt(int64(c.Int(tag)))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.SetInt(int64(c.Int(name)))
+			return nil
+		}
+	case reflect.Int32:
+		return func(c ContextReader, name string, field reflect.Value) error {
+			if r, ok := c.(Int32Reader); ok {
+				field.SetInt(int64(r.Int32(name)))
+			} else {
+				field.SetInt(int64(int32(c.Int64(name))))
+			}
+			return nil
+		}
 	case reflect.Int64:
-		field.SetInt(c.Int64(tag))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.SetInt(c.Int64(name))
+			return nil
+		}
 	case reflect.Uint:
-		field.SetUint(uint64(c.Uint(tag)))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.SetUint(uint64(c.Uint(name)))
+			return nil
+		}
+	case reflect.Uint32:
+		return func(c ContextReader, name string, field reflect.Value) error {
+			if r, ok := c.(Uint32Reader); ok {
+				field.SetUint(uint64(r.Uint32(name)))
+			} else {
+				field.SetUint(uint64(uint32(c.Uint64(name))))
+			}
+			return nil
+		}
 	case reflect.Uint64:
-		field.SetUint(c.Uint64(tag))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.SetUint(c.Uint64(name))
+			return nil
+		}
 	case reflect.Bool:
-		field.SetBool(c.Bool(tag))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.SetBool(c.Bool(name))
+			return nil
+		}
+	case reflect.Float32:
+		return func(c ContextReader, name string, field reflect.Value) error {
+			if r, ok := c.(Float32Reader); ok {
+				field.SetFloat(float64(r.Float32(name)))
+			} else {
+				field.SetFloat(float64(float32(c.Float64(name))))
+			}
+			return nil
+		}
 	case reflect.Float64:
-		field.SetFloat(c.Float64(tag))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.SetFloat(c.Float64(name))
+			return nil
+		}
 	case reflect.Slice:
-		setSliceValue(c, tag, field)
+		return sliceSetter(t)
+	case reflect.Map:
+		if t == reflect.TypeOf(map[string]string{}) {
+			return func(c ContextReader, name string, field reflect.Value) error {
+				if r, ok := c.(StringMapReader); ok {
+					field.Set(reflect.ValueOf(r.StringMap(name)))
+				}
+				return nil
+			}
+		}
 	}
+	return nil
 }
 
-// setSliceValue handles setting slice values from CLI flags.
+// sliceSetter returns the setter of slice fields.
 // It supports various slice types like []string, []int, etc.
-func setSliceValue(c ContextReader, tag string, field reflect.Value) {
-	switch field.Type() {
+func sliceSetter(t reflect.Type) setter {
+	switch t {
 	case reflect.TypeOf([]string{}):
-		field.Set(reflect.ValueOf(c.StringSlice(tag)))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.Set(reflect.ValueOf(c.StringSlice(name)))
+			return nil
+		}
 	case reflect.TypeOf([]int{}):
-		field.Set(reflect.ValueOf(c.IntSlice(tag)))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.Set(reflect.ValueOf(c.IntSlice(name)))
+			return nil
+		}
 	case reflect.TypeOf([]int64{}):
-		field.Set(reflect.ValueOf(c.Int64Slice(tag)))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.Set(reflect.ValueOf(c.Int64Slice(name)))
+			return nil
+		}
 	case reflect.TypeOf([]uint{}):
-		field.Set(reflect.ValueOf(c.UintSlice(tag)))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.Set(reflect.ValueOf(c.UintSlice(name)))
+			return nil
+		}
 	case reflect.TypeOf([]uint64{}):
-		field.Set(reflect.ValueOf(c.Uint64Slice(tag)))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.Set(reflect.ValueOf(c.Uint64Slice(name)))
+			return nil
+		}
 	case reflect.TypeOf([]float64{}):
-		field.Set(reflect.ValueOf(c.Float64Slice(tag)))
+		return func(c ContextReader, name string, field reflect.Value) error {
+			field.Set(reflect.ValueOf(c.Float64Slice(name)))
+			return nil
+		}
 	}
+	return nil
 }
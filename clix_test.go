@@ -258,3 +258,23 @@ func TestMissingValues(t *testing.T) {
 	assert.Nil(t, sliceConfig.Uint64Slice)
 	assert.Nil(t, sliceConfig.Float64Slice)
 }
+
+func TestParseNarrowTypesFallback(t *testing.T) {
+	type Config struct {
+		Int32Val   int32   `cli:"int32-val"`
+		Uint32Val  uint32  `cli:"uint32-val"`
+		Float32Val float32 `cli:"float32-val"`
+	}
+
+	// The mock does not implement the optional readers, so the 64-bit accessors are used
+	ctx := newMockContext()
+	ctx.int64Map["int32-val"] = -42
+	ctx.uint64Map["uint32-val"] = 42
+	ctx.float64Map["float32-val"] = 0.5
+
+	config := Parse[Config](ctx)
+
+	assert.Equal(t, int32(-42), config.Int32Val)
+	assert.Equal(t, uint32(42), config.Uint32Val)
+	assert.Equal(t, float32(0.5), config.Float32Val)
+}
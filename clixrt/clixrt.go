@@ -0,0 +1,64 @@
+// Package clixrt is the runtime of the code written by clixgen. It holds the reader interfaces, which clix
+// re-exports, and the helpers the generated code calls, so that generated code does not import clix and with
+// it reflect, urfave/cli and yaml.
+package clixrt
+
+import (
+	"encoding"
+	"time"
+)
+
+// ContextReader defines the interface for reading values from a CLI context.
+// This abstraction allows for easier testing by allowing mock implementations.
+type ContextReader interface {
+	String(name string) string
+	Int(name string) int
+	Int64(name string) int64
+	Uint(name string) uint
+	Uint64(name string) uint64
+	Bool(name string) bool
+	Float64(name string) float64
+	Timestamp(name string) *time.Time
+	Duration(name string) time.Duration
+	StringSlice(name string) []string
+	IntSlice(name string) []int
+	Int64Slice(name string) []int64
+	UintSlice(name string) []uint
+	Uint64Slice(name string) []uint64
+	Float64Slice(name string) []float64
+}
+
+// Optional ContextReader capabilities for the values that have no ContextReader method of their own.
+type (
+	Int32Reader interface {
+		Int32(name string) int32
+	}
+	Uint32Reader interface {
+		Uint32(name string) uint32
+	}
+	Float32Reader interface {
+		Float32(name string) float32
+	}
+	StringMapReader interface {
+		StringMap(name string) map[string]string
+	}
+)
+
+// ParseTexts sets *dst to the texts read with UnmarshalText. It leaves *dst alone when texts is nil and sets it
+// to nil when one of the texts fails to parse, the generated code reports no errors.
+func ParseTexts[T any, P interface {
+	*T
+	encoding.TextUnmarshaler
+}](dst *[]T, texts []string) {
+	if texts == nil {
+		return
+	}
+	list := make([]T, len(texts))
+	for i, s := range texts {
+		if P(&list[i]).UnmarshalText([]byte(s)) != nil {
+			*dst = nil
+			return
+		}
+	}
+	*dst = list
+}
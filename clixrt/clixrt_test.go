@@ -0,0 +1,21 @@
+package clixrt
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTexts(t *testing.T) {
+	var allow []netip.Prefix
+	ParseTexts(&allow, nil)
+	assert.Nil(t, allow)
+
+	ParseTexts(&allow, []string{"10.0.0.0/8", "fd00::/8"})
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}, allow)
+
+	// a text that fails to parse leaves no list, the generated code reports no errors
+	ParseTexts(&allow, []string{"10.0.0.0/8", "10.0.0.0/33"})
+	assert.Nil(t, allow)
+}
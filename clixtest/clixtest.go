@@ -0,0 +1,248 @@
+// Package clixtest provides fakes and helpers for testing code that uses clix.
+//
+// Reader builds a fake clix.ContextReader from plain values, and RunV3 runs a real urfave v3 command
+// in-process to test flag wiring end to end.
+package clixtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/modfin/clix"
+	"github.com/modfin/clix/clixv3"
+	cliv3 "github.com/urfave/cli/v3"
+)
+
+// FakeReader is a clix.ContextReader holding values set by the test.
+// It also implements the optional clix.IsSetReader, Int32Reader, Uint32Reader, Float32Reader, StringMapReader,
+// FlagNamesReader and ArgsReader.
+//
+// Values are converted to the type of the accessor that reads them: numbers convert between numeric types,
+// and strings are parsed for durations (time.ParseDuration) and timestamps (RFC3339).
+// A value that can not be converted panics, as it is a mistake in the test.
+type FakeReader struct {
+	values map[string]any
+	args   []string
+}
+
+// Reader returns an empty FakeReader
+// Usage:
+//
+//	r := clixtest.Reader().Set("db-port", 5432).SetSlice("tags", "a", "b")
+//	cfg := clix.Parse[Config](r)
+func Reader() *FakeReader {
+	return &FakeReader{values: map[string]any{}}
+}
+
+// Set sets the value of a flag, slices and maps may be given as well
+func (r *FakeReader) Set(name string, value any) *FakeReader {
+	r.values[name] = value
+	return r
+}
+
+// SetSlice sets the value of a slice flag from its elements
+func (r *FakeReader) SetSlice(name string, values ...any) *FakeReader {
+	r.values[name] = values
+	return r
+}
+
+// IsSet reports whether the flag was set with Set or SetSlice
+func (r *FakeReader) IsSet(name string) bool {
+	_, ok := r.values[name]
+	return ok
+}
+
+// SetArgs sets the positional arguments
+func (r *FakeReader) SetArgs(args ...string) *FakeReader {
+	r.args = args
+	return r
+}
+
+// Args returns the positional arguments set with SetArgs
+func (r *FakeReader) Args() []string {
+	return r.args
+}
+
+// FlagNames returns the names of the flags set with Set or SetSlice, sorted
+func (r *FakeReader) FlagNames() []string {
+	names := make([]string, 0, len(r.values))
+	for name := range r.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// V3 returns a view of the reader that implements clix.CommandReaderV3,
+// for code that takes a v3 command. It is needed as the two interfaces disagree on the signature of Timestamp.
+func (r *FakeReader) V3() clix.CommandReaderV3 {
+	return fakeV3{r}
+}
+
+func (r *FakeReader) String(name string) string          { return value[string](r, name) }
+func (r *FakeReader) Int(name string) int                { return value[int](r, name) }
+func (r *FakeReader) Int32(name string) int32            { return value[int32](r, name) }
+func (r *FakeReader) Int64(name string) int64            { return value[int64](r, name) }
+func (r *FakeReader) Uint(name string) uint              { return value[uint](r, name) }
+func (r *FakeReader) Uint32(name string) uint32          { return value[uint32](r, name) }
+func (r *FakeReader) Uint64(name string) uint64          { return value[uint64](r, name) }
+func (r *FakeReader) Bool(name string) bool              { return value[bool](r, name) }
+func (r *FakeReader) Float32(name string) float32        { return value[float32](r, name) }
+func (r *FakeReader) Float64(name string) float64        { return value[float64](r, name) }
+func (r *FakeReader) Duration(name string) time.Duration { return value[time.Duration](r, name) }
+func (r *FakeReader) StringSlice(name string) []string   { return slice[string](r, name) }
+func (r *FakeReader) IntSlice(name string) []int         { return slice[int](r, name) }
+func (r *FakeReader) Int64Slice(name string) []int64     { return slice[int64](r, name) }
+func (r *FakeReader) UintSlice(name string) []uint       { return slice[uint](r, name) }
+func (r *FakeReader) Uint64Slice(name string) []uint64   { return slice[uint64](r, name) }
+func (r *FakeReader) Float64Slice(name string) []float64 { return slice[float64](r, name) }
+
+func (r *FakeReader) StringMap(name string) map[string]string {
+	return value[map[string]string](r, name)
+}
+
+func (r *FakeReader) Timestamp(name string) *time.Time {
+	if !r.IsSet(name) {
+		return nil
+	}
+	if t, ok := r.values[name].(*time.Time); ok {
+		return t
+	}
+	t := value[time.Time](r, name)
+	return &t
+}
+
+func value[T any](r *FakeReader, name string) T {
+	var zero T
+	v, ok := r.values[name]
+	if !ok {
+		return zero
+	}
+	return convert[T](name, v)
+}
+
+func slice[T any](r *FakeReader, name string) []T {
+	v, ok := r.values[name]
+	if !ok {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("clixtest: flag %q holds %T, not a slice", name, v))
+	}
+	out := make([]T, rv.Len())
+	for i := range out {
+		out[i] = convert[T](name, rv.Index(i).Interface())
+	}
+	return out
+}
+
+func convert[T any](name string, v any) T {
+	if t, ok := v.(T); ok {
+		return t
+	}
+	var zero T
+	target := reflect.TypeOf(zero)
+
+	if s, ok := v.(string); ok {
+		switch any(zero).(type) {
+		case time.Duration:
+			if d, err := time.ParseDuration(s); err == nil {
+				return any(d).(T)
+			}
+		case time.Time:
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return any(t).(T)
+			}
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.IsValid() && isNumber(rv.Kind()) && isNumber(target.Kind()) {
+		return rv.Convert(target).Interface().(T)
+	}
+	panic(fmt.Sprintf("clixtest: flag %q holds %T, it can not be read as %s", name, v, target))
+}
+
+func isNumber(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Float64
+}
+
+// fakeV3 is the CommandReaderV3 view of a FakeReader
+type fakeV3 struct {
+	r *FakeReader
+}
+
+func (f fakeV3) String(name string) string               { return f.r.String(name) }
+func (f fakeV3) Int(name string) int                     { return f.r.Int(name) }
+func (f fakeV3) Int32(name string) int32                 { return f.r.Int32(name) }
+func (f fakeV3) Int64(name string) int64                 { return f.r.Int64(name) }
+func (f fakeV3) Uint(name string) uint                   { return f.r.Uint(name) }
+func (f fakeV3) Uint32(name string) uint32               { return f.r.Uint32(name) }
+func (f fakeV3) Uint64(name string) uint64               { return f.r.Uint64(name) }
+func (f fakeV3) Bool(name string) bool                   { return f.r.Bool(name) }
+func (f fakeV3) Float(name string) float64               { return f.r.Float64(name) }
+func (f fakeV3) Float32(name string) float32             { return f.r.Float32(name) }
+func (f fakeV3) Float64(name string) float64             { return f.r.Float64(name) }
+func (f fakeV3) Duration(name string) time.Duration      { return f.r.Duration(name) }
+func (f fakeV3) StringSlice(name string) []string        { return f.r.StringSlice(name) }
+func (f fakeV3) IntSlice(name string) []int              { return f.r.IntSlice(name) }
+func (f fakeV3) Int64Slice(name string) []int64          { return f.r.Int64Slice(name) }
+func (f fakeV3) UintSlice(name string) []uint            { return f.r.UintSlice(name) }
+func (f fakeV3) Uint64Slice(name string) []uint64        { return f.r.Uint64Slice(name) }
+func (f fakeV3) FloatSlice(name string) []float64        { return f.r.Float64Slice(name) }
+func (f fakeV3) Float64Slice(name string) []float64      { return f.r.Float64Slice(name) }
+func (f fakeV3) StringMap(name string) map[string]string { return f.r.StringMap(name) }
+func (f fakeV3) IsSet(name string) bool                  { return f.r.IsSet(name) }
+
+func (f fakeV3) Timestamp(name string) time.Time {
+	if t := f.r.Timestamp(name); t != nil {
+		return *t
+	}
+	return time.Time{}
+}
+
+// RunV3 runs a urfave v3 command with the given flags, command line arguments and environment, and returns the T
+// parsed and validated by clix.TryParseCommand in its action. The program name is not part of args.
+// Flags defaults to clixv3.Flags[T]() when nil.
+//
+// Besides flags, args and env, RunV3 takes the testing.TB of the test as its first argument. The environment is
+// set with t.Setenv, so it is restored when the test ends, even when the test fails or exits early, and a test
+// that runs in parallel fails instead of racing on the environment. RunV3 can therefore not be used from parallel
+// tests or their parents.
+// Usage:
+//
+//	cfg, err := clixtest.RunV3[Config](t, nil, []string{"--port", "80"}, map[string]string{"HOST": "example.com"})
+func RunV3[T any](t testing.TB, flags []cliv3.Flag, args []string, env map[string]string) (T, error) {
+	t.Helper()
+	var cfg T
+	if flags == nil {
+		var err error
+		if flags, err = clixv3.Flags[T](); err != nil {
+			return cfg, err
+		}
+	}
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	cmd := &cliv3.Command{
+		Name:           "clixtest",
+		Flags:          flags,
+		Writer:         io.Discard,
+		ErrWriter:      io.Discard,
+		ExitErrHandler: func(context.Context, *cliv3.Command, error) {},
+		Action: func(_ context.Context, cmd *cliv3.Command) error {
+			var err error
+			cfg, err = clix.TryParseCommand[T](cmd)
+			return err
+		},
+	}
+	err := cmd.Run(context.Background(), append([]string{"clixtest"}, args...))
+	return cfg, err
+}
@@ -0,0 +1,130 @@
+package clixtest
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/modfin/clix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cliv3 "github.com/urfave/cli/v3"
+)
+
+type Config struct {
+	Host    string            `cli:"host" cli-env:"CLIXTEST_HOST" cli-default:"localhost"`
+	Workers int32             `cli:"workers"`
+	Ratio   float32           `cli:"ratio"`
+	Timeout time.Duration     `cli:"timeout"`
+	Start   time.Time         `cli:"start"`
+	Tags    []string          `cli:"tags"`
+	Weights []float64         `cli:"weights"`
+	Labels  map[string]string `cli:"labels"`
+	DB      struct {
+		Port int `cli:"port" cli-max:"65535"`
+	} `cli-prefix:"db-"`
+}
+
+func TestReader(t *testing.T) {
+	r := Reader().
+		Set("host", "example.com").
+		Set("workers", 4).
+		Set("ratio", 0.5).
+		Set("timeout", "1m").
+		Set("start", "2024-01-02T03:04:05Z").
+		SetSlice("tags", "a", "b").
+		SetSlice("weights", 1, 2.5).
+		Set("labels", map[string]string{"env": "dev"}).
+		Set("db-port", 5432)
+
+	want := Config{
+		Host:    "example.com",
+		Workers: 4,
+		Ratio:   0.5,
+		Timeout: time.Minute,
+		Start:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:    []string{"a", "b"},
+		Weights: []float64{1, 2.5},
+		Labels:  map[string]string{"env": "dev"},
+	}
+	want.DB.Port = 5432
+
+	assert.Equal(t, want, clix.Parse[Config](r))
+	assert.Equal(t, want, clix.ParseCommand[Config](r.V3()))
+	assert.True(t, r.IsSet("db-port"))
+	assert.False(t, r.IsSet("missing"))
+	assert.Nil(t, r.Timestamp("missing"))
+}
+
+func TestReaderValidation(t *testing.T) {
+	_, err := clix.TryParse[Config](Reader().Set("db-port", 70000))
+	assert.EqualError(t, err, "--db-port: 70000 is greater than 65535")
+}
+
+func TestReaderList(t *testing.T) {
+	type Upstream struct {
+		Host string `cli:"host"`
+		Port int    `cli:"port" cli-default:"80"`
+	}
+	type Proxy struct {
+		Upstreams []Upstream `cli-prefix:"upstream-"`
+	}
+
+	r := Reader().Set("upstream-0-host", "a").Set("upstream-1-host", "b").Set("upstream-1-port", 8080)
+	assert.Equal(t, []string{"upstream-0-host", "upstream-1-host", "upstream-1-port"}, r.FlagNames())
+
+	cfg, err := clix.TryParse[Proxy](r)
+	require.NoError(t, err)
+	assert.Equal(t, []Upstream{{Host: "a", Port: 80}, {Host: "b", Port: 8080}}, cfg.Upstreams)
+}
+
+func TestReaderArgs(t *testing.T) {
+	type Copy struct {
+		Src   string   `cli-arg:"0"`
+		Files []string `cli-args:"rest"`
+		Force bool     `cli:"force"`
+	}
+
+	cfg, err := clix.TryParse[Copy](Reader().Set("force", true).SetArgs("a", "b", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, Copy{Src: "a", Files: []string{"b", "c"}, Force: true}, cfg)
+}
+
+func TestReaderPanicsOnWrongType(t *testing.T) {
+	assert.PanicsWithValue(t, `clixtest: flag "host" holds int, it can not be read as string`, func() {
+		Reader().Set("host", 1).String("host")
+	})
+	assert.Panics(t, func() {
+		Reader().Set("tags", "a").StringSlice("tags")
+	})
+}
+
+func TestRunV3(t *testing.T) {
+	cfg, err := RunV3[Config](t, nil, []string{"--workers", "8", "--tags", "a", "--tags", "b", "--db-port", "5432"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, int32(8), cfg.Workers)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, 5432, cfg.DB.Port)
+
+	t.Run("env", func(t *testing.T) {
+		cfg, err := RunV3[Config](t, nil, nil, map[string]string{"CLIXTEST_HOST": "example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", cfg.Host)
+	})
+	_, set := os.LookupEnv("CLIXTEST_HOST")
+	assert.False(t, set, "environment is restored when the test ends")
+}
+
+func TestRunV3Errors(t *testing.T) {
+	_, err := RunV3[Config](t, nil, []string{"--db-port", "70000"}, nil)
+	assert.EqualError(t, err, "--db-port: 70000 is greater than 65535")
+
+	_, err = RunV3[Config](t, nil, []string{"--unknown"}, nil)
+	assert.Error(t, err)
+
+	// custom flags, only --host is wired
+	cfg, err := RunV3[Config](t, []cliv3.Flag{&cliv3.StringFlag{Name: "host"}}, []string{"--host", "h"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "h", cfg.Host)
+}
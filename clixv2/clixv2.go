@@ -0,0 +1,28 @@
+// Package clixv2 adapts clix to urfave/cli v2. A cli.Context is a clix.ContextReader, so clix.Parse reads it as is,
+// the package wraps typed actions.
+package clixv2
+
+import (
+	"github.com/modfin/clix"
+	cli "github.com/urfave/cli/v2"
+)
+
+// Action wraps a typed action as a urfave v2 cli.ActionFunc.
+// The config is parsed and validated with clix.TryParse before fn is called, a failure is returned as a *clix.UsageError.
+// example
+//
+//	app := &cli.App{
+//		Flags:  flags,
+//		Action: clixv2.Action(func(c *cli.Context, cfg Config) error {
+//			return run(cfg)
+//		}),
+//	}
+func Action[A any](fn func(c *cli.Context, cfg A) error, opts ...clix.Option) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		cfg, err := clix.TryParse[A](c, opts...)
+		if err != nil {
+			return &clix.UsageError{Err: err}
+		}
+		return fn(c, cfg)
+	}
+}
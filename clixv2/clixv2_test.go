@@ -0,0 +1,44 @@
+package clixv2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/modfin/clix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cli "github.com/urfave/cli/v2"
+)
+
+type actionConfig struct {
+	Name string `cli:"name" cli-required:"true"`
+	Port int    `cli:"port" cli-max:"65535"`
+}
+
+func TestAction(t *testing.T) {
+	var got actionConfig
+	app := &cli.App{
+		Name:           "test",
+		ExitErrHandler: func(*cli.Context, error) {},
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name"},
+			&cli.IntFlag{Name: "port"},
+		},
+		Action: Action(func(c *cli.Context, cfg actionConfig) error {
+			got = cfg
+			return nil
+		}),
+	}
+
+	require.NoError(t, app.Run([]string{"test", "--name", "app", "--port", "80"}))
+	assert.Equal(t, actionConfig{Name: "app", Port: 80}, got)
+
+	err := app.Run([]string{"test", "--port", "70000"})
+	var ue *clix.UsageError
+	require.True(t, errors.As(err, &ue))
+	assert.Equal(t, clix.UsageExitCode, ue.ExitCode())
+	assert.Equal(t, "Incorrect Usage: --name: required flag is not set; --port: 70000 is greater than 65535", err.Error())
+
+	var exitCoder cli.ExitCoder
+	assert.True(t, errors.As(err, &exitCoder))
+}
@@ -20,12 +20,56 @@ type CommandReaderV3 interface {
 	FloatSlice(name string) []float64
 }
 
+// Richer accessors of a v3 cli.Command that are not part of CommandReaderV3.
+// The V3 proxy uses them when the command implements them, so that e.g. an Int64Flag is read
+// through Int64 rather than truncated through Int.
+type (
+	int64ReaderV3 interface {
+		Int64(name string) int64
+	}
+	uint64ReaderV3 interface {
+		Uint64(name string) uint64
+	}
+	float64ReaderV3 interface {
+		Float64(name string) float64
+	}
+	int64SliceReaderV3 interface {
+		Int64Slice(name string) []int64
+	}
+	uint64SliceReaderV3 interface {
+		Uint64Slice(name string) []uint64
+	}
+	float64SliceReaderV3 interface {
+		Float64Slice(name string) []float64
+	}
+	valueReaderV3 interface {
+		Value(name string) any
+	}
+)
+
+// holds reports whether the flag name of c holds a T. The sized accessors of a v3 command, such as Int64,
+// return the zero value for a flag of another size, an int64 field bound to an IntFlag is read through Int.
+// Commands that can not tell are assumed to hold a T.
+func holds[T any](c CommandReaderV3, name string) bool {
+	r, ok := c.(valueReaderV3)
+	if !ok {
+		return true
+	}
+	_, ok = r.Value(name).(T)
+	return ok
+}
+
 // V3 converts a v3 CommandReaderV3 to a v2 ContextReader
 // example
 //
 //	 func(ctx context.Context, cmd *cli.Command) error {
 //		  config := clix.Parse[Config](clix.V3(cmd))
+//
+// The returned reader implements IsSetReader only if cmd does.
 func V3(cmd CommandReaderV3) ContextReader {
+	if _, ok := cmd.(IsSetReader); ok {
+		return &proxy3to2Set{proxy3to2{c: cmd}}
+	}
 	return &proxy3to2{c: cmd}
 }
 
@@ -35,14 +79,36 @@ func V3(cmd CommandReaderV3) ContextReader {
 //
 //	 func(ctx context.Context, cmd *cli.Command) error {
 //		  config := clix.ParseCommand[Config](cmd)
-func ParseCommand[A any](cmd CommandReaderV3) A {
-	return Parse[A](V3(cmd))
+func ParseCommand[A any](cmd CommandReaderV3, opts ...Option) A {
+	return Parse[A](V3(cmd), opts...)
 }
 
 type proxy3to2 struct {
 	c CommandReaderV3
 }
 
+// proxy3to2Set is a proxy3to2 for commands that can tell whether a flag was set
+type proxy3to2Set struct {
+	proxy3to2
+}
+
+func (p proxy3to2Set) IsSet(name string) bool {
+	return p.c.(IsSetReader).IsSet(name)
+}
+
+// FlagNames returns the flag names of the command, or nil when it can not list them
+func (p proxy3to2) FlagNames() []string {
+	if r, ok := p.c.(FlagNamesReader); ok {
+		return r.FlagNames()
+	}
+	return nil
+}
+
+// Args returns the positional arguments of the command
+func (p proxy3to2) Args() []string {
+	return readArgs(p.c)
+}
+
 func (p proxy3to2) String(name string) string {
 	return p.c.String(name)
 }
@@ -52,16 +118,35 @@ func (p proxy3to2) Int(name string) int {
 }
 
 func (p proxy3to2) Int64(name string) int64 {
+	if r, ok := p.c.(int64ReaderV3); ok && holds[int64](p.c, name) {
+		return r.Int64(name)
+	}
 	return int64(p.c.Int(name))
 }
 
+func (p proxy3to2) Int32(name string) int32 {
+	if r, ok := p.c.(Int32Reader); ok && holds[int32](p.c, name) {
+		return r.Int32(name)
+	}
+	return int32(p.Int64(name))
+}
+
 func (p proxy3to2) Uint(name string) uint {
 	return p.c.Uint(name)
 }
 
 func (p proxy3to2) Uint64(name string) uint64 {
+	if r, ok := p.c.(uint64ReaderV3); ok && holds[uint64](p.c, name) {
+		return r.Uint64(name)
+	}
 	return uint64(p.c.Uint(name))
+}
 
+func (p proxy3to2) Uint32(name string) uint32 {
+	if r, ok := p.c.(Uint32Reader); ok && holds[uint32](p.c, name) {
+		return r.Uint32(name)
+	}
+	return uint32(p.Uint64(name))
 }
 
 func (p proxy3to2) Bool(name string) bool {
@@ -69,9 +154,19 @@ func (p proxy3to2) Bool(name string) bool {
 }
 
 func (p proxy3to2) Float64(name string) float64 {
+	if r, ok := p.c.(float64ReaderV3); ok && holds[float64](p.c, name) {
+		return r.Float64(name)
+	}
 	return p.c.Float(name)
 }
 
+func (p proxy3to2) Float32(name string) float32 {
+	if r, ok := p.c.(Float32Reader); ok && holds[float32](p.c, name) {
+		return r.Float32(name)
+	}
+	return float32(p.Float64(name))
+}
+
 func (p proxy3to2) Timestamp(name string) *time.Time {
 	t := p.c.Timestamp(name)
 	if t.IsZero() {
@@ -93,6 +188,9 @@ func (p proxy3to2) IntSlice(name string) []int {
 }
 
 func (p proxy3to2) Int64Slice(name string) []int64 {
+	if r, ok := p.c.(int64SliceReaderV3); ok && holds[[]int64](p.c, name) {
+		return r.Int64Slice(name)
+	}
 	return toInt64Slice(p.c.IntSlice(name))
 }
 
@@ -101,13 +199,26 @@ func (p proxy3to2) UintSlice(name string) []uint {
 }
 
 func (p proxy3to2) Uint64Slice(name string) []uint64 {
+	if r, ok := p.c.(uint64SliceReaderV3); ok && holds[[]uint64](p.c, name) {
+		return r.Uint64Slice(name)
+	}
 	return toUint64Slice(p.c.UintSlice(name))
 }
 
 func (p proxy3to2) Float64Slice(name string) []float64 {
+	if r, ok := p.c.(float64SliceReaderV3); ok && holds[[]float64](p.c, name) {
+		return r.Float64Slice(name)
+	}
 	return p.c.FloatSlice(name)
 }
 
+func (p proxy3to2) StringMap(name string) map[string]string {
+	if r, ok := p.c.(StringMapReader); ok {
+		return r.StringMap(name)
+	}
+	return nil
+}
+
 func toInt64Slice(sl []int) []int64 {
 	ints := make([]int64, len(sl))
 	for i, v := range sl {
@@ -0,0 +1,51 @@
+package clixv3
+
+import (
+	"context"
+
+	"github.com/modfin/clix"
+	cli "github.com/urfave/cli/v3"
+)
+
+// Action wraps a typed action as a urfave v3 cli.ActionFunc.
+// The config is parsed and validated with clix.TryParseCommand before fn is called, a failure is returned as a *clix.UsageError.
+// example
+//
+//	cmd := &cli.Command{
+//		Flags:  flags,
+//		Action: clixv3.Action(func(ctx context.Context, cmd *cli.Command, cfg Config) error {
+//			return run(ctx, cfg)
+//		}),
+//	}
+func Action[A any](fn func(ctx context.Context, cmd *cli.Command, cfg A) error, opts ...clix.Option) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		cfg, err := clix.TryParseCommand[A](cmd, opts...)
+		if err != nil {
+			return &clix.UsageError{Err: err}
+		}
+		return fn(ctx, cmd, cfg)
+	}
+}
+
+// Before returns a v3 Before hook that parses A once, with clix.TryParseCommand, and attaches it to the context
+// passed on to the actions of the command and all its subcommands.
+// A parse failure is returned as a *clix.UsageError.
+// example
+//
+//	cmd := &cli.Command{
+//		Flags:  flags,
+//		Before: clixv3.Before[Config](),
+//		Commands: []*cli.Command{{
+//			Name: "serve",
+//			Action: func(ctx context.Context, cmd *cli.Command) error {
+//				cfg, _ := clix.FromContext[Config](ctx)
+//				...
+func Before[A any](opts ...clix.Option) cli.BeforeFunc {
+	return func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+		cfg, err := clix.TryParseCommand[A](cmd, opts...)
+		if err != nil {
+			return ctx, &clix.UsageError{Err: err}
+		}
+		return clix.WithConfig(ctx, cfg), nil
+	}
+}
@@ -0,0 +1,87 @@
+package clixv3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modfin/clix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cli "github.com/urfave/cli/v3"
+)
+
+type actionConfig struct {
+	Name string `cli:"name" cli-required:"true"`
+	Port int    `cli:"port" cli-max:"65535"`
+}
+
+func TestAction(t *testing.T) {
+	var got actionConfig
+	cmd := &cli.Command{
+		Name:           "test",
+		ExitErrHandler: func(context.Context, *cli.Command, error) {},
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name"},
+			&cli.IntFlag{Name: "port"},
+		},
+		Action: Action(func(ctx context.Context, cmd *cli.Command, cfg actionConfig) error {
+			got = cfg
+			return nil
+		}),
+	}
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"test", "--name", "app", "--port", "80"}))
+	assert.Equal(t, actionConfig{Name: "app", Port: 80}, got)
+
+	err := cmd.Run(context.Background(), []string{"test", "--name", "app", "--port", "70000"})
+	var exitCoder cli.ExitCoder
+	require.True(t, errors.As(err, &exitCoder))
+	assert.Equal(t, clix.UsageExitCode, exitCoder.ExitCode())
+
+	var pe *clix.ParseError
+	assert.True(t, errors.As(err, &pe))
+}
+
+func TestBefore(t *testing.T) {
+	parsed := 0
+	before := Before[actionConfig]()
+	counting := func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+		parsed++
+		return before(ctx, cmd)
+	}
+
+	var got []actionConfig
+	action := func(ctx context.Context, cmd *cli.Command) error {
+		cfg, ok := clix.FromContext[actionConfig](ctx)
+		require.True(t, ok)
+		got = append(got, cfg)
+		return nil
+	}
+
+	// v3 commands keep flag state between runs, so every run gets a fresh one
+	newCmd := func() *cli.Command {
+		return &cli.Command{
+			Name:           "test",
+			ExitErrHandler: func(context.Context, *cli.Command, error) {},
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "name"},
+				&cli.IntFlag{Name: "port"},
+			},
+			Before: counting,
+			Commands: []*cli.Command{
+				{Name: "serve", Action: action},
+				{Name: "check", Commands: []*cli.Command{{Name: "config", Action: action}}},
+			},
+		}
+	}
+
+	require.NoError(t, newCmd().Run(context.Background(), []string{"test", "--name", "app", "serve"}))
+	require.NoError(t, newCmd().Run(context.Background(), []string{"test", "--name", "app", "check", "config", "--port", "80"}))
+	assert.Equal(t, []actionConfig{{Name: "app"}, {Name: "app", Port: 80}}, got)
+	assert.Equal(t, 2, parsed)
+
+	err := newCmd().Run(context.Background(), []string{"test", "serve"})
+	var ue *clix.UsageError
+	assert.True(t, errors.As(err, &ue))
+}
@@ -0,0 +1,99 @@
+package clixv3
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/modfin/clix"
+	cli "github.com/urfave/cli/v3"
+)
+
+// Command builds a urfave v3 command tree from the struct A.
+//
+// Fields tagged `cli-cmd:"name,alias..."` are subcommands, their type is a struct (or pointer to one)
+// holding the flags of the subcommand and, in turn, its own subcommands. `cli-usage` on the field is the usage of the command.
+// Flags of a command are persistent, so they can be given after any of its subcommands.
+// The ArgsUsage of a command lists its positional arguments, the fields tagged `cli-arg` and `cli-args`.
+//
+// When a command runs, the root struct and every struct on the path to the command are populated and validated
+// as with clix.TryParse, and Run is called on the command struct with its parent struct, see clix.RunCommand.
+// Commands that do not implement clix.Runner only show help.
+// example
+//
+//	type Root struct {
+//		Verbose bool `cli:"verbose"`
+//		DB      struct {
+//			DSN     string  `cli:"dsn"`
+//			Migrate Migrate `cli-cmd:"migrate" cli-usage:"run migrations"`
+//		} `cli-cmd:"db"`
+//	}
+//
+//	func (m *Migrate) Run(ctx context.Context, parent any) error { ... }
+//
+//	cmd, err := clixv3.Command[Root]("tool")
+//	err = cmd.Run(ctx, os.Args)
+func Command[A any](name string, opts ...clix.Option) (*cli.Command, error) {
+	t := reflect.TypeOf((*A)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+	return buildCommand(t, t, name, nil, opts)
+}
+
+var runnerType = reflect.TypeOf((*clix.Runner)(nil)).Elem()
+
+// buildCommand builds the command for the struct t, found at the field index path from the root type
+func buildCommand(root, t reflect.Type, name string, path [][]int, opts []clix.Option) (*cli.Command, error) {
+	flags, err := flagsOf(t, opts)
+	if err != nil {
+		return nil, fmt.Errorf("command %s: %w", name, err)
+	}
+	cmd := &cli.Command{
+		Name:      name,
+		Flags:     flags,
+		ArgsUsage: clix.DescribeType(t, opts...).ArgsUsage(),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		names := commandNames(fieldType.Tag.Get("cli-cmd"))
+		if len(names) == 0 || !fieldType.IsExported() {
+			continue
+		}
+		subType := fieldType.Type
+		if subType.Kind() == reflect.Ptr {
+			subType = subType.Elem()
+		}
+		if subType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("command %s: field %s is not a struct", names[0], fieldType.Name)
+		}
+
+		sub, err := buildCommand(root, subType, names[0], append(append([][]int{}, path...), fieldType.Index), opts)
+		if err != nil {
+			return nil, err
+		}
+		sub.Aliases = names[1:]
+		sub.Usage = fieldType.Tag.Get("cli-usage")
+		cmd.Commands = append(cmd.Commands, sub)
+	}
+
+	if reflect.PointerTo(t).Implements(runnerType) {
+		cmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+			return clix.RunCommand(ctx, root, path, clix.V3(cmd), opts...)
+		}
+	}
+	return cmd, nil
+}
+
+// commandNames returns the name and aliases of a `cli-cmd:"name,alias..."` tag
+func commandNames(tag string) []string {
+	var names []string
+	for _, n := range strings.Split(tag, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
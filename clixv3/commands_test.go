@@ -0,0 +1,127 @@
+package clixv3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modfin/clix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cli "github.com/urfave/cli/v3"
+)
+
+type toolCmd struct {
+	Verbose bool        `cli:"verbose"`
+	DB      dbCmd       `cli-cmd:"db" cli-usage:"database commands"`
+	Copy    copyCmd     `cli-cmd:"copy"`
+	Version *versionCmd `cli-cmd:"version,v"`
+}
+
+type dbCmd struct {
+	DSN     string     `cli:"dsn" cli-required:"true"`
+	Migrate migrateCmd `cli-cmd:"migrate" cli-usage:"run migrations"`
+}
+
+type migrateCmd struct {
+	Steps int `cli:"steps" cli-default:"1" cli-max:"10"`
+}
+
+type copyCmd struct {
+	Src   string   `cli-arg:"0" cli-required:"true"`
+	Dst   string   `cli-arg:"1,dst" cli-required:"true"`
+	Files []string `cli-args:"rest"`
+}
+
+type versionCmd struct{}
+
+// ran records the last command run, with the config it saw and the root config of the context
+var ran struct {
+	name   string
+	self   any
+	parent any
+	root   toolCmd
+}
+
+func (m *migrateCmd) Run(ctx context.Context, parent any) error {
+	ran.name, ran.self, ran.parent = "migrate", *m, *parent.(*dbCmd)
+	ran.root, _ = clix.FromContext[toolCmd](ctx)
+	return nil
+}
+
+func (c *copyCmd) Run(ctx context.Context, parent any) error {
+	ran.name, ran.self, ran.parent = "copy", *c, *parent.(*toolCmd)
+	return nil
+}
+
+func (v *versionCmd) Run(ctx context.Context, parent any) error {
+	ran.name, ran.self, ran.parent = "version", *v, *parent.(*toolCmd)
+	return nil
+}
+
+func TestCommandTree(t *testing.T) {
+	cmd, err := Command[toolCmd]("tool")
+	require.NoError(t, err)
+
+	assert.Equal(t, "tool", cmd.Name)
+	assert.Nil(t, cmd.Action)
+	require.Len(t, cmd.Commands, 3)
+
+	db := cmd.Commands[0]
+	assert.Equal(t, "db", db.Name)
+	assert.Equal(t, "database commands", db.Usage)
+	assert.Nil(t, db.Action)
+	assert.Equal(t, "dsn", db.Flags[0].Names()[0])
+
+	migrate := db.Commands[0]
+	assert.Equal(t, "run migrations", migrate.Usage)
+	assert.NotNil(t, migrate.Action)
+	assert.Equal(t, 1, migrate.Flags[0].(*cli.IntFlag).Value)
+
+	assert.Equal(t, "<src> <dst> [files...]", cmd.Commands[1].ArgsUsage)
+	assert.Equal(t, []string{"v"}, cmd.Commands[2].Aliases)
+}
+
+func TestCommandRun(t *testing.T) {
+	cmd, err := Command[toolCmd]("tool")
+	require.NoError(t, err)
+
+	// Parent flags are persistent and may be given after the subcommand
+	err = cmd.Run(context.Background(), []string{"tool", "db", "migrate", "--dsn", "postgres://", "--steps", "3", "--verbose"})
+	require.NoError(t, err)
+	assert.Equal(t, "migrate", ran.name)
+	assert.Equal(t, migrateCmd{Steps: 3}, ran.self)
+	assert.Equal(t, "postgres://", ran.parent.(dbCmd).DSN)
+	assert.True(t, ran.root.Verbose)
+
+	err = cmd.Run(context.Background(), []string{"tool", "--verbose", "v"})
+	require.NoError(t, err)
+	assert.Equal(t, "version", ran.name)
+	assert.True(t, ran.parent.(toolCmd).Verbose)
+
+	err = cmd.Run(context.Background(), []string{"tool", "copy", "a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, copyCmd{Src: "a", Dst: "b", Files: []string{"c"}}, ran.self)
+}
+
+func TestCommandValidation(t *testing.T) {
+	cmd, err := Command[toolCmd]("tool")
+	require.NoError(t, err)
+	cmd.ExitErrHandler = func(context.Context, *cli.Command, error) {}
+
+	err = cmd.Run(context.Background(), []string{"tool", "db", "migrate", "--steps", "11"})
+	var ue *clix.UsageError
+	require.True(t, errors.As(err, &ue))
+	assert.Equal(t, "Incorrect Usage: --dsn: required flag is not set; --steps: 11 is greater than 10", err.Error())
+}
+
+func TestCommandErrors(t *testing.T) {
+	_, err := Command[string]("tool")
+	assert.EqualError(t, err, "string is not a struct")
+
+	type bad struct {
+		Sub int `cli-cmd:"sub"`
+	}
+	_, err = Command[bad]("tool")
+	assert.EqualError(t, err, "command sub: field Sub is not a struct")
+}
@@ -0,0 +1,108 @@
+// Package clixv3 adapts clix to urfave/cli v3: it creates the flags that clix.Parse reads, wraps typed actions
+// and builds command trees from structs. The clix package itself depends on no cli framework, commands are read
+// through clix.V3 and clix.TryParseCommand.
+package clixv3
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/modfin/clix"
+	cli "github.com/urfave/cli/v3"
+)
+
+// Flags creates the urfave v3 flags that Parse reads into A, see clix.FlagDefs.
+// Aliases become aliases of the flag, while each deprecated name gets its own hidden flag,
+// so that Parse can tell which name was used.
+// example
+//
+//	flags, err := clixv3.Flags[Config]()
+//	cmd := &cli.Command{Name: "mytool", Flags: flags, Action: clixv3.Action(run)}
+func Flags[A any](opts ...clix.Option) ([]cli.Flag, error) {
+	return flagsOf(reflect.TypeOf((*A)(nil)).Elem(), opts)
+}
+
+func flagsOf(t reflect.Type, opts []clix.Option) ([]cli.Flag, error) {
+	defs, err := clix.FlagDefsType(t, opts...)
+	if err != nil {
+		return nil, err
+	}
+	flags := make([]cli.Flag, len(defs))
+	for i, d := range defs {
+		if flags[i], err = flag(d); err != nil {
+			return nil, fmt.Errorf("--%s: %w", d.Name, err)
+		}
+	}
+	return flags, nil
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// flag creates the v3 flag of d
+func flag(d clix.FlagDef) (cli.Flag, error) {
+	switch d.Type {
+	case durationType:
+		return newFlag(&cli.DurationFlag{}, d), nil
+	case timeType:
+		return newFlag(&cli.TimestampFlag{Config: cli.TimestampConfig{Layouts: d.Layouts, Timezone: d.Location}}, d), nil
+	}
+	switch d.Type.Kind() {
+	case reflect.String:
+		return newFlag(&cli.StringFlag{}, d), nil
+	case reflect.Bool:
+		return newFlag(&cli.BoolFlag{}, d), nil
+	case reflect.Int:
+		return newFlag(&cli.IntFlag{}, d), nil
+	case reflect.Int32:
+		return newFlag(&cli.Int32Flag{}, d), nil
+	case reflect.Int64:
+		return newFlag(&cli.Int64Flag{}, d), nil
+	case reflect.Uint:
+		return newFlag(&cli.UintFlag{}, d), nil
+	case reflect.Uint32:
+		return newFlag(&cli.Uint32Flag{}, d), nil
+	case reflect.Uint64:
+		return newFlag(&cli.Uint64Flag{}, d), nil
+	case reflect.Float32:
+		return newFlag(&cli.Float32Flag{}, d), nil
+	case reflect.Float64:
+		return newFlag(&cli.Float64Flag{}, d), nil
+	case reflect.Map:
+		return newFlag(&cli.StringMapFlag{}, d), nil
+	case reflect.Slice:
+		switch d.Type.Elem().Kind() {
+		case reflect.String:
+			return newFlag(&cli.StringSliceFlag{}, d), nil
+		case reflect.Int:
+			return newFlag(&cli.IntSliceFlag{}, d), nil
+		case reflect.Int64:
+			return newFlag(&cli.Int64SliceFlag{}, d), nil
+		case reflect.Uint:
+			return newFlag(&cli.UintSliceFlag{}, d), nil
+		case reflect.Uint64:
+			return newFlag(&cli.Uint64SliceFlag{}, d), nil
+		case reflect.Float64:
+			return newFlag(&cli.FloatSliceFlag{}, d), nil
+		}
+	}
+	return nil, fmt.Errorf("no v3 flag for type %s", d.Type)
+}
+
+// newFlag fills in a flag of any v3 flag type from the definition
+func newFlag[T any, C any, VC cli.ValueCreator[T, C]](fl *cli.FlagBase[T, C, VC], d clix.FlagDef) cli.Flag {
+	fl.Name = d.Name
+	fl.Aliases = d.Aliases
+	fl.Usage = d.Usage
+	fl.Hidden = d.Hidden
+	if len(d.Env) > 0 {
+		fl.Sources = cli.EnvVars(d.Env...)
+	}
+	if d.Value != nil {
+		fl.Value = d.Value.(T)
+	}
+	return fl
+}
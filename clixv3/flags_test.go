@@ -0,0 +1,56 @@
+package clixv3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cli "github.com/urfave/cli/v3"
+)
+
+type flagsConfig struct {
+	Name    string            `cli:"name" cli-usage:"name of the app" cli-env:"APP_NAME" cli-default:"app"`
+	Addr    string            `cli:"addr,a|deprecated=bind"`
+	Port    int               `cli:"port" cli-default:"8080"`
+	Small   int32             `cli:"small"`
+	Count   uint64            `cli:"count"`
+	Ratio   float32           `cli:"ratio"`
+	Debug   bool              `cli:"debug"`
+	Timeout time.Duration     `cli:"timeout" cli-default:"5s"`
+	Start   time.Time         `cli:"start" cli-layout:"DateOnly" cli-tz:"Europe/Stockholm"`
+	Tags    []string          `cli:"tags" cli-default:"a,b"`
+	Weights []float64         `cli:"weights"`
+	Labels  map[string]string `cli:"labels"`
+}
+
+func TestFlags(t *testing.T) {
+	flags, err := Flags[flagsConfig]()
+	require.NoError(t, err)
+	require.Len(t, flags, 13)
+
+	assert.Equal(t, &cli.StringFlag{Name: "name", Usage: "name of the app", Sources: cli.EnvVars("APP_NAME"), Value: "app"}, flags[0])
+	assert.Equal(t, &cli.StringFlag{Name: "addr", Aliases: []string{"a"}}, flags[1])
+	assert.Equal(t, &cli.StringFlag{Name: "bind", Usage: "deprecated, use --addr", Hidden: true}, flags[2])
+	assert.Equal(t, &cli.IntFlag{Name: "port", Value: 8080}, flags[3])
+	assert.IsType(t, &cli.Int32Flag{}, flags[4])
+	assert.IsType(t, &cli.Uint64Flag{}, flags[5])
+	assert.IsType(t, &cli.Float32Flag{}, flags[6])
+	assert.IsType(t, &cli.BoolFlag{}, flags[7])
+	assert.Equal(t, &cli.DurationFlag{Name: "timeout", Value: 5 * time.Second}, flags[8])
+	start := flags[9].(*cli.TimestampFlag)
+	assert.Equal(t, []string{time.DateOnly}, start.Config.Layouts)
+	assert.Equal(t, "Europe/Stockholm", start.Config.Timezone.String())
+	assert.Equal(t, &cli.StringSliceFlag{Name: "tags", Value: []string{"a", "b"}}, flags[10])
+	assert.IsType(t, &cli.FloatSliceFlag{}, flags[11])
+	assert.IsType(t, &cli.StringMapFlag{}, flags[12])
+}
+
+func TestFlagsErrors(t *testing.T) {
+	type Config struct {
+		Port int      `cli:"port" cli-default:"eighty"`
+		Ch   chan int `cli:"ch"`
+	}
+	_, err := Flags[Config]()
+	assert.EqualError(t, err, `--port: invalid default "eighty": strconv.ParseInt: parsing "eighty": invalid syntax; --ch: no flag for type chan int`)
+}
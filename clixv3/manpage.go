@@ -0,0 +1,32 @@
+package clixv3
+
+import (
+	"github.com/modfin/clix"
+	cli "github.com/urfave/cli/v3"
+)
+
+// ManPage renders a roff man page for a v3 command tree whose root flags are parsed into A, see clix.ManPageTree.
+// Name, usage and description are taken from cmd, and a COMMANDS section is added for its visible subcommands.
+func ManPage[A any](cmd *cli.Command, section int) string {
+	return clix.ManPageTree[A](manCommand(cmd), section)
+}
+
+// manCommand describes cmd, its visible flags and its visible subcommands
+func manCommand(cmd *cli.Command) clix.ManCommand {
+	m := clix.ManCommand{Name: cmd.Name, Usage: cmd.Usage, Description: cmd.Description}
+	for _, fl := range cmd.Flags {
+		if vf, ok := fl.(cli.VisibleFlag); ok && !vf.IsVisible() {
+			continue
+		}
+		mf := clix.ManFlag{Names: fl.Names()}
+		if df, ok := fl.(cli.DocGenerationFlag); ok {
+			mf.Usage = df.GetUsage()
+			mf.Env = df.GetEnvVars()
+		}
+		m.Flags = append(m.Flags, mf)
+	}
+	for _, sub := range cmd.VisibleCommands() {
+		m.Commands = append(m.Commands, manCommand(sub))
+	}
+	return m
+}
@@ -0,0 +1,46 @@
+package clixv3
+
+import (
+	"testing"
+
+	"github.com/modfin/clix"
+	"github.com/stretchr/testify/assert"
+	cli "github.com/urfave/cli/v3"
+)
+
+func TestManPage(t *testing.T) {
+	cmd := &cli.Command{
+		Name:        "mytool",
+		Usage:       "does things",
+		Description: "mytool does things",
+		Commands: []*cli.Command{
+			{
+				Name:  "migrate",
+				Usage: "run migrations",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "dry-run", Aliases: []string{"n"}, Usage: "only print"},
+					&cli.StringFlag{Name: "dir", Sources: cli.EnvVars("MIGRATE_DIR")},
+					&cli.StringFlag{Name: "secret", Hidden: true},
+				},
+			},
+			{Name: "hidden", Hidden: true},
+		},
+	}
+	want := clix.ManCommand{
+		Name:        "mytool",
+		Usage:       "does things",
+		Description: "mytool does things",
+		Commands: []clix.ManCommand{
+			{
+				Name:  "migrate",
+				Usage: "run migrations",
+				Flags: []clix.ManFlag{
+					{Names: []string{"dry-run", "n"}, Usage: "only print", Env: []string{}},
+					{Names: []string{"dir"}, Env: []string{"MIGRATE_DIR"}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, want, manCommand(cmd))
+	assert.Equal(t, clix.ManPageTree[actionConfig](want, 8), ManPage[actionConfig](cmd, 8))
+}
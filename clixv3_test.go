@@ -1,10 +1,13 @@
 package clix
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cli "github.com/urfave/cli/v3"
 )
 
 // mockCommandReaderV3 implements the CommandReaderV3 interface for testing
@@ -127,3 +130,153 @@ func TestParseWithCommandReaderV3(t *testing.T) {
 	assert.Equal(t, []int{10, 20, 30}, config.Values)
 	assert.Equal(t, []float64{0.1, 0.2}, config.Rate)
 }
+
+// richMockCommandReaderV3 adds the optional accessors of a v3 cli.Command to mockCommandReaderV3
+type richMockCommandReaderV3 struct {
+	mockCommandReaderV3
+	int64Map     map[string]int64
+	uint64Map    map[string]uint64
+	int32Map     map[string]int32
+	float32Map   map[string]float32
+	stringMapMap map[string]map[string]string
+	setMap       map[string]bool
+}
+
+func (m *richMockCommandReaderV3) Int64(name string) int64     { return m.int64Map[name] }
+func (m *richMockCommandReaderV3) Uint64(name string) uint64   { return m.uint64Map[name] }
+func (m *richMockCommandReaderV3) Int32(name string) int32     { return m.int32Map[name] }
+func (m *richMockCommandReaderV3) Float32(name string) float32 { return m.float32Map[name] }
+func (m *richMockCommandReaderV3) IsSet(name string) bool      { return m.setMap[name] }
+func (m *richMockCommandReaderV3) StringMap(name string) map[string]string {
+	return m.stringMapMap[name]
+}
+
+// TestV3OptionalAccessors tests that the proxy prefers the richer accessors when they exist
+func TestV3OptionalAccessors(t *testing.T) {
+	cmdReader := &richMockCommandReaderV3{
+		mockCommandReaderV3: mockCommandReaderV3{
+			intMap:   map[string]int{"big": 1},
+			uintMap:  map[string]uint{"ubig": 2},
+			floatMap: map[string]float64{"ratio": 0.5},
+		},
+		int64Map:     map[string]int64{"big": 9223372036854775807},
+		uint64Map:    map[string]uint64{"ubig": 18446744073709551615},
+		int32Map:     map[string]int32{"small": -7},
+		float32Map:   map[string]float32{"ratio": 0.25},
+		stringMapMap: map[string]map[string]string{"labels": {"a": "b"}},
+		setMap:       map[string]bool{"big": true},
+	}
+
+	ctxReader := V3(cmdReader)
+	assert.Equal(t, int64(9223372036854775807), ctxReader.Int64("big"))
+	assert.Equal(t, uint64(18446744073709551615), ctxReader.Uint64("ubig"))
+	assert.Equal(t, int32(-7), ctxReader.(Int32Reader).Int32("small"))
+	assert.Equal(t, float32(0.25), ctxReader.(Float32Reader).Float32("ratio"))
+	assert.Equal(t, map[string]string{"a": "b"}, ctxReader.(StringMapReader).StringMap("labels"))
+
+	setReader, ok := ctxReader.(IsSetReader)
+	assert.True(t, ok)
+	assert.True(t, setReader.IsSet("big"))
+	assert.False(t, setReader.IsSet("small"))
+
+	// Without the optional accessors the proxy falls back and does not claim to know set-ness
+	plain := V3(&mockCommandReaderV3{intMap: map[string]int{"big": 1}, floatMap: map[string]float64{"ratio": 0.5}})
+	assert.Equal(t, int64(1), plain.Int64("big"))
+	assert.Equal(t, int32(1), plain.(Int32Reader).Int32("big"))
+	assert.Equal(t, float32(0.5), plain.(Float32Reader).Float32("ratio"))
+	_, ok = plain.(IsSetReader)
+	assert.False(t, ok)
+}
+
+// TestParseWithCliV3Command tests the proxy against a real v3 command using 64-bit and 32-bit flags
+func TestParseWithCliV3Command(t *testing.T) {
+	type Config struct {
+		Big    int64             `cli:"big"`
+		UBig   uint64            `cli:"ubig"`
+		Small  int32             `cli:"small"`
+		Ratio  float32           `cli:"ratio"`
+		Labels map[string]string `cli:"labels"`
+		IDs    []int64           `cli:"ids"`
+	}
+
+	var config Config
+	cmd := &cli.Command{
+		Name: "test",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "big"},
+			&cli.Uint64Flag{Name: "ubig"},
+			&cli.Int32Flag{Name: "small"},
+			&cli.Float32Flag{Name: "ratio"},
+			&cli.StringMapFlag{Name: "labels"},
+			&cli.Int64SliceFlag{Name: "ids"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			config = ParseCommand[Config](cmd)
+			return nil
+		},
+	}
+	err := cmd.Run(context.Background(), []string{"test",
+		"--big", "9223372036854775807",
+		"--ubig", "18446744073709551615",
+		"--small", "-7",
+		"--ratio", "0.25",
+		"--labels", "a=b",
+		"--ids", "1", "--ids", "9223372036854775807",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Config{
+		Big:    9223372036854775807,
+		UBig:   18446744073709551615,
+		Small:  -7,
+		Ratio:  0.25,
+		Labels: map[string]string{"a": "b"},
+		IDs:    []int64{1, 9223372036854775807},
+	}, config)
+}
+
+func TestParseWithCliV3CommandOtherWidths(t *testing.T) {
+	type Config struct {
+		N     int64     `cli:"n"`
+		U     uint64    `cli:"u"`
+		Small int32     `cli:"small"`
+		Ratio float32   `cli:"ratio"`
+		IDs   []int64   `cli:"ids"`
+		UIDs  []uint64  `cli:"uids"`
+		Rates []float64 `cli:"rates"`
+	}
+
+	// flags of the width of int, uint and float64 bound to fields of other widths
+	var config Config
+	cmd := &cli.Command{
+		Name: "test",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "n"},
+			&cli.UintFlag{Name: "u"},
+			&cli.IntFlag{Name: "small"},
+			&cli.FloatFlag{Name: "ratio"},
+			&cli.IntSliceFlag{Name: "ids"},
+			&cli.UintSliceFlag{Name: "uids"},
+			&cli.FloatSliceFlag{Name: "rates"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			config = ParseCommand[Config](cmd)
+			return nil
+		},
+	}
+	err := cmd.Run(context.Background(), []string{"test",
+		"--n", "5", "--u", "6", "--small", "-7", "--ratio", "0.5",
+		"--ids", "1", "--ids", "2", "--uids", "3", "--rates", "0.25",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Config{
+		N:     5,
+		U:     6,
+		Small: -7,
+		Ratio: 0.5,
+		IDs:   []int64{1, 2},
+		UIDs:  []uint64{3},
+		Rates: []float64{0.25},
+	}, config)
+}
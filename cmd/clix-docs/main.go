@@ -0,0 +1,57 @@
+// Command clix-docs writes a markdown reference of the flags a clix config struct is parsed from.
+// The struct is loaded from source with go/packages, the application itself is never built or run.
+//
+// Usage:
+//
+//	//go:generate go run github.com/modfin/clix/cmd/clix-docs -type Config -title mytool -o FLAGS.md
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/modfin/clix"
+	"github.com/modfin/clix/internal/typesconv"
+)
+
+func main() {
+	var (
+		typeName = flag.String("type", "", "name of the config struct (required)")
+		pkg      = flag.String("pkg", ".", "package pattern containing the type")
+		title    = flag.String("title", "", "title of the document, defaults to the type name")
+		desc     = flag.String("description", "", "paragraph rendered below the title")
+		out      = flag.String("o", "", "output file, defaults to stdout")
+	)
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*pkg, *typeName, *title, *desc, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "clix-docs:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkg, typeName, title, desc, out string) error {
+	named, _, err := typesconv.Load("", pkg, typeName)
+	if err != nil {
+		return err
+	}
+	rt, err := typesconv.Reflect(named)
+	if err != nil {
+		return err
+	}
+	if title == "" {
+		title = typeName
+	}
+
+	md := clix.DocsMarkdownType(rt, clix.DocsOptions{Title: title, Description: desc})
+	if out == "" {
+		_, err = os.Stdout.WriteString(md)
+		return err
+	}
+	return os.WriteFile(out, []byte(md), 0o644)
+}
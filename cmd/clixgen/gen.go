@@ -0,0 +1,547 @@
+package main
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"go/format"
+	"go/types"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/modfin/clix"
+	"github.com/modfin/clix/internal/typesconv"
+)
+
+// generate returns the formatted source of the generated file and the directory of the package
+func generate(dir, pattern, typeName string) ([]byte, string, error) {
+	named, pkg, err := typesconv.Load(dir, pattern, typeName)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(pkg.GoFiles) == 0 {
+		return nil, "", fmt.Errorf("package %s has no Go files", pkg.PkgPath)
+	}
+	if field := unionField(named, "", map[types.Type]bool{}); field != "" {
+		return nil, "", errUnsupported(field, "unions")
+	}
+	rt, err := typesconv.Reflect(named)
+	if err != nil {
+		return nil, "", err
+	}
+	plan, err := clix.CompileType(rt)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", typeName, err)
+	}
+
+	g := &generator{
+		pkg:     pkg.Types,
+		named:   named,
+		imports: map[string]string{"github.com/modfin/clix/clixrt": "clixrt"},
+	}
+	body, err := g.file(typeName, plan.Spec())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by clixgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg.Name)
+	out.WriteString("import (\n")
+	// standard library first, as goimports would group them
+	var std, other []string
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	for i, group := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 {
+			out.WriteString("\n")
+		}
+		for _, path := range group {
+			if name := g.imports[path]; name != filepath.Base(path) {
+				fmt.Fprintf(&out, "\t%s %q\n", name, path)
+			} else {
+				fmt.Fprintf(&out, "\t%q\n", path)
+			}
+		}
+	}
+	out.WriteString(")\n\n")
+	out.Write(body)
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, "", fmt.Errorf("formatting generated code: %w\n%s", err, out.Bytes())
+	}
+	return src, filepath.Dir(pkg.GoFiles[0]), nil
+}
+
+// unionField returns the path of the first field tagged cli-union in the struct t, or its nested structs.
+// Interface fields have no reflect mirror, so the union would be dropped silently rather than rejected by the plan.
+func unionField(t types.Type, path string, seen map[types.Type]bool) string {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || seen[t] {
+		return ""
+	}
+	seen[t] = true
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		name := f.Name()
+		if path != "" {
+			name = path + "." + name
+		}
+		if _, ok := reflect.StructTag(st.Tag(i)).Lookup("cli-union"); ok {
+			return name
+		}
+		if field := unionField(f.Type(), name, seen); field != "" {
+			return field
+		}
+	}
+	return ""
+}
+
+// unsupported returns an error for the first field of spec that clixgen does not generate code for.
+// They are listed in the package doc, unions are found by generate as they have no reflect mirror.
+func unsupported(spec clix.SectionSpec) error {
+	var err error
+	fail := func(where, what string) {
+		if err == nil {
+			err = errUnsupported(where, what)
+		}
+	}
+	spec.WalkLists(func(_ clix.SectionSpec, l clix.ListSpec) { fail(l.Field, "slices of structs") })
+	spec.WalkMaps(func(_ clix.SectionSpec, m clix.MapSpec) { fail(m.Field, "maps of structs") })
+	spec.WalkArgs(func(_ clix.SectionSpec, a clix.ArgSpec) { fail(a.Field, "positional arguments") })
+	spec.Walk(func(_ clix.SectionSpec, f clix.FlagSpec) {
+		switch {
+		case len(f.Aliases) > 0 || len(f.Deprecated) > 0:
+			fail("--"+f.Name, "aliases and deprecated names")
+		case len(f.Layouts) > 0 || f.TZ != "":
+			fail("--"+f.Name, "cli-layout and cli-tz")
+		case len(f.Path) > 0:
+			fail("--"+f.Name, "cli-path")
+		case len(f.Schemes) > 0 || f.Port != "":
+			fail("--"+f.Name, "cli-scheme and cli-port")
+		}
+	})
+	return err
+}
+
+func errUnsupported(where, what string) error {
+	return fmt.Errorf("%s: clixgen does not support %s", where, what)
+}
+
+type generator struct {
+	pkg     *types.Package
+	named   *types.Named
+	imports map[string]string // import path to package name
+}
+
+func (g *generator) file(typeName string, spec clix.SectionSpec) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Parse%s populates a %s from the reader, it is the reflection-free equivalent of clix.Parse[%s](c).\n", typeName, typeName, typeName)
+	fmt.Fprintf(&b, "func Parse%s(c clixrt.ContextReader) %s {\n", typeName, typeName)
+	fmt.Fprintf(&b, "\tvar cfg %s\n", typeName)
+	err := unsupported(spec)
+	spec.Walk(func(_ clix.SectionSpec, f clix.FlagSpec) {
+		if err != nil {
+			return
+		}
+		var stmt string
+		if stmt, err = g.assign(f); stmt != "" {
+			b.WriteString("\t" + stmt + "\n")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.WriteString("\treturn cfg\n}\n\n")
+
+	g.imports["github.com/urfave/cli/v3"] = "cli"
+	fmt.Fprintf(&b, "// %sFlags returns the flags %s is parsed from, the same as clixv3.Flags[%s]().\n", typeName, typeName, typeName)
+	fmt.Fprintf(&b, "func %sFlags() []cli.Flag {\n\treturn []cli.Flag{\n", typeName)
+	seen := map[string]bool{}
+	spec.Walk(func(_ clix.SectionSpec, f clix.FlagSpec) {
+		if err != nil || seen[f.Name] {
+			return
+		}
+		seen[f.Name] = true
+		var lit string
+		if lit, err = g.flag(f); err == nil {
+			b.WriteString("\t\t" + lit + ",\n")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.WriteString("\t}\n}\n")
+	return b.Bytes(), nil
+}
+
+// assign returns the statement setting the field of f, mirroring the setters of clix.Parse.
+// Fields that clix.Parse skips get no statement.
+func (g *generator) assign(f clix.FlagSpec) (string, error) {
+	parts := strings.Split(f.Field, ".")
+	for i := 1; i < len(parts); i++ {
+		if st, _ := typesconv.FieldType(g.named, strings.Join(parts[:i], ".")); st != nil {
+			if _, ok := st.Underlying().(*types.Pointer); ok {
+				return "", errUnsupported("--"+f.Name, "struct pointers, such as "+strings.Join(parts[:i], "."))
+			}
+		}
+	}
+	ft, ok := typesconv.FieldType(g.named, f.Field)
+	if !ok {
+		return "", fmt.Errorf("field %s not found", f.Field)
+	}
+	field := "cfg." + f.Field
+	name := strconv.Quote(f.Name)
+
+	switch {
+	case isNamed(ft, "time", "Duration"):
+		return fmt.Sprintf("%s = c.Duration(%s)", field, name), nil
+	case isNamed(ft, "time", "Time"):
+		return fmt.Sprintf("if t := c.Timestamp(%s); t != nil {\n\t\t%s = *t\n\t}", name, field), nil
+	}
+	if p, ok := ft.(*types.Pointer); ok && isNamed(p.Elem(), "time", "Time") {
+		return fmt.Sprintf("if t := c.Timestamp(%s); t != nil {\n\t\t%s = t\n\t}", name, field), nil
+	}
+
+	// URLs, regexps and templates are string flags parsed with url.Parse, regexp.Compile and template.Parse
+	if parse, ok := g.parser(ft); ok {
+		return fmt.Sprintf("if s := c.String(%s); s != \"\" {\n\t\t%s, _ = %s(s)\n\t}", name, field, parse), nil
+	}
+	if sl, ok := ft.Underlying().(*types.Slice); ok {
+		if parse, ok := g.parser(sl.Elem()); ok {
+			return fmt.Sprintf("if texts := c.StringSlice(%s); texts != nil {\n\t\tlist := make([]%s, len(texts))\n"+
+				"\t\tfor i, s := range texts {\n\t\t\tvar err error\n\t\t\tif list[i], err = %s(s); err != nil {\n\t\t\t\tlist = nil\n\t\t\t\tbreak\n\t\t\t}\n\t\t}\n"+
+				"\t\t%s = list\n\t}", name, types.TypeString(sl.Elem(), g.qualifier), parse, field), nil
+		}
+	}
+
+	// types read from their text, such as clix.ByteSize, are string flags parsed with UnmarshalText
+	if isTextType(ft) {
+		return fmt.Sprintf("if s := c.String(%s); s != \"\" {\n\t\t_ = %s.UnmarshalText([]byte(s))\n\t}", name, field), nil
+	}
+	if sl, ok := ft.Underlying().(*types.Slice); ok && isTextType(sl.Elem()) {
+		return fmt.Sprintf("clixrt.ParseTexts(&%s, c.StringSlice(%s))", field, name), nil
+	}
+
+	switch u := ft.Underlying().(type) {
+	case *types.Basic:
+		switch u.Kind() {
+		case types.String:
+			return fmt.Sprintf("%s = %s", field, g.convert(ft, "c.String("+name+")", types.String)), nil
+		case types.Int:
+			return fmt.Sprintf("%s = %s", field, g.convert(ft, "c.Int("+name+")", types.Int)), nil
+		case types.Int64:
+			return fmt.Sprintf("%s = %s", field, g.convert(ft, "c.Int64("+name+")", types.Int64)), nil
+		case types.Uint:
+			return fmt.Sprintf("%s = %s", field, g.convert(ft, "c.Uint("+name+")", types.Uint)), nil
+		case types.Uint64:
+			return fmt.Sprintf("%s = %s", field, g.convert(ft, "c.Uint64("+name+")", types.Uint64)), nil
+		case types.Bool:
+			return fmt.Sprintf("%s = %s", field, g.convert(ft, "c.Bool("+name+")", types.Bool)), nil
+		case types.Float64:
+			return fmt.Sprintf("%s = %s", field, g.convert(ft, "c.Float64("+name+")", types.Float64)), nil
+		case types.Int32:
+			return g.optional(ft, field, name, "Int32Reader", "Int32", "int32(c.Int64("+name+"))", types.Int32), nil
+		case types.Uint32:
+			return g.optional(ft, field, name, "Uint32Reader", "Uint32", "uint32(c.Uint64("+name+"))", types.Uint32), nil
+		case types.Float32:
+			return g.optional(ft, field, name, "Float32Reader", "Float32", "float32(c.Float64("+name+"))", types.Float32), nil
+		}
+	case *types.Slice:
+		// clix.Parse only sets unnamed slices of these element types
+		if _, isNamed := ft.(*types.Named); isNamed {
+			return "", nil
+		}
+		accessors := map[types.BasicKind]string{
+			types.String: "StringSlice", types.Int: "IntSlice", types.Int64: "Int64Slice",
+			types.Uint: "UintSlice", types.Uint64: "Uint64Slice", types.Float64: "Float64Slice",
+		}
+		if elem, ok := u.Elem().(*types.Basic); ok && accessors[elem.Kind()] != "" {
+			return fmt.Sprintf("%s = c.%s(%s)", field, accessors[elem.Kind()], name), nil
+		}
+	case *types.Map:
+		if _, isNamed := ft.(*types.Named); !isNamed && types.Identical(u, types.NewMap(types.Typ[types.String], types.Typ[types.String])) {
+			return fmt.Sprintf("if r, ok := c.(clixrt.StringMapReader); ok {\n\t\t%s = r.StringMap(%s)\n\t}", field, name), nil
+		}
+	}
+	return "", nil
+}
+
+// optional returns an assignment that uses an optional reader interface when the reader implements it
+func (g *generator) optional(ft types.Type, field, name, iface, method, fallback string, kind types.BasicKind) string {
+	return fmt.Sprintf("if r, ok := c.(clixrt.%s); ok {\n\t\t%s = %s\n\t} else {\n\t\t%s = %s\n\t}",
+		iface, field, g.convert(ft, "r."+method+"("+name+")", kind), field, g.convert(ft, fallback, kind))
+}
+
+// convert wraps expr, of the basic type kind, in a conversion to t unless it already has that type
+func (g *generator) convert(t types.Type, expr string, kind types.BasicKind) string {
+	if types.Identical(t, types.Typ[kind]) {
+		return expr
+	}
+	return types.TypeString(t, g.qualifier) + "(" + expr + ")"
+}
+
+func (g *generator) qualifier(p *types.Package) string {
+	if p.Path() == g.pkg.Path() {
+		return ""
+	}
+	g.imports[p.Path()] = p.Name()
+	return p.Name()
+}
+
+// flag returns the flag literal of f, mirroring clixv3.Flags
+func (g *generator) flag(f clix.FlagSpec) (string, error) {
+	kind, err := flagKind(f.GoType)
+	if err != nil {
+		return "", fmt.Errorf("--%s: %w", f.Name, err)
+	}
+
+	fields := []string{"Name: " + strconv.Quote(f.Name)}
+	if f.Usage != "" {
+		fields = append(fields, "Usage: "+strconv.Quote(f.Usage))
+	}
+	if len(f.Env) > 0 {
+		quoted := make([]string, len(f.Env))
+		for i, e := range f.Env {
+			quoted[i] = strconv.Quote(e)
+		}
+		fields = append(fields, "Sources: cli.EnvVars("+strings.Join(quoted, ", ")+")")
+	}
+	if kind == "Timestamp" {
+		g.imports["time"] = "time"
+		fields = append(fields, "Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}")
+	}
+	if f.Default != "" {
+		lit, err := g.literal(f.GoType, f.Default)
+		if err != nil {
+			return "", fmt.Errorf("--%s: invalid default %q: %w", f.Name, f.Default, err)
+		}
+		fields = append(fields, "Value: "+lit)
+	}
+	return "&cli." + kind + "Flag{" + strings.Join(fields, ", ") + "}", nil
+}
+
+// flagKind returns the v3 flag type name, without the Flag suffix, used by clixv3.Flags for t
+func flagKind(t reflect.Type) (string, error) {
+	switch {
+	case textType(t):
+		return "String", nil
+	case t.Kind() == reflect.Slice && textType(t.Elem()):
+		return "StringSlice", nil
+	}
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return "Duration", nil
+	case reflect.TypeOf(time.Time{}), reflect.PointerTo(reflect.TypeOf(time.Time{})):
+		return "Timestamp", nil
+	}
+	kinds := map[reflect.Kind]string{
+		reflect.String: "String", reflect.Bool: "Bool",
+		reflect.Int: "Int", reflect.Int32: "Int32", reflect.Int64: "Int64",
+		reflect.Uint: "Uint", reflect.Uint32: "Uint32", reflect.Uint64: "Uint64",
+		reflect.Float32: "Float32", reflect.Float64: "Float64",
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String {
+			return "StringMap", nil
+		}
+	case reflect.Slice:
+		slices := map[reflect.Kind]string{
+			reflect.String: "StringSlice", reflect.Int: "IntSlice", reflect.Int64: "Int64Slice",
+			reflect.Uint: "UintSlice", reflect.Uint64: "Uint64Slice", reflect.Float64: "FloatSlice",
+		}
+		if k, ok := slices[t.Elem().Kind()]; ok {
+			return k, nil
+		}
+	default:
+		if k, ok := kinds[t.Kind()]; ok {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("no v3 flag for type %s", t)
+}
+
+// literal returns the Go literal of the default value s, typed as the value of the flag for t
+func (g *generator) literal(t reflect.Type, s string) (string, error) {
+	switch t {
+	case urlType:
+		_, err := url.Parse(s)
+		return strconv.Quote(s), err
+	case regexpType:
+		_, err := regexp.Compile(s)
+		return strconv.Quote(s), err
+	case templateType:
+		_, err := template.New("").Parse(s)
+		return strconv.Quote(s), err
+	}
+	if textType(t) {
+		// the flag holds the text, which must parse as t
+		err := reflect.New(t).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		return strconv.Quote(s), err
+	}
+	if t.Kind() == reflect.Slice && textType(t.Elem()) {
+		var items []string
+		for _, part := range strings.Split(s, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			item, err := g.literal(t.Elem(), part)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return "[]string{" + strings.Join(items, ", ") + "}", nil
+	}
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return "", err
+		}
+		g.imports["time"] = "time"
+		return fmt.Sprintf("time.Duration(%d)", int64(d)), nil
+	case reflect.TypeOf(time.Time{}), reflect.PointerTo(reflect.TypeOf(time.Time{})):
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", err
+		}
+		g.imports["time"] = "time"
+		loc := "time.UTC"
+		if _, offset := ts.Zone(); ts.Location() != time.UTC {
+			loc = fmt.Sprintf("time.FixedZone(%q, %d)", "", offset)
+		}
+		return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, %s)",
+			ts.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), loc), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return strconv.Quote(s), nil
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		return strconv.FormatBool(v), err
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 0, t.Bits())
+		return strconv.FormatInt(v, 10), err
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(s, 0, t.Bits())
+		return strconv.FormatUint(v, 10), err
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, t.Bits())
+		return strconv.FormatFloat(v, 'g', -1, t.Bits()), err
+	case reflect.Slice:
+		var items []string
+		for _, part := range strings.Split(s, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			item, err := g.literal(t.Elem(), part)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return "[]" + t.Elem().Kind().String() + "{" + strings.Join(items, ", ") + "}", nil
+	case reflect.Map:
+		var items []string
+		for _, pair := range strings.Split(s, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return "", fmt.Errorf("invalid map entry %q, expected key=value", pair)
+			}
+			items = append(items, strconv.Quote(key)+": "+strconv.Quote(value))
+		}
+		return "map[string]string{" + strings.Join(items, ", ") + "}", nil
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+var (
+	urlType      = reflect.TypeOf((*url.URL)(nil))
+	regexpType   = reflect.TypeOf((*regexp.Regexp)(nil))
+	templateType = reflect.TypeOf((*template.Template)(nil))
+)
+
+// textTypes are the packages and names of the value types clix.Parse reads with UnmarshalText
+var textTypes = [][2]string{
+	{"github.com/modfin/clix", "ByteSize"},
+	{"github.com/modfin/clix", "Percent"},
+	{"github.com/modfin/clix", "Rate"},
+	{"github.com/modfin/clix", "HostPort"},
+	{"net/netip", "Addr"},
+	{"net/netip", "AddrPort"},
+	{"net/netip", "Prefix"},
+}
+
+// textType reports whether t is read from its text by clix.Parse, one of textTypes, *url.URL, *regexp.Regexp
+// or *template.Template
+func textType(t reflect.Type) bool {
+	if t == urlType || t == regexpType || t == templateType {
+		return true
+	}
+	for _, n := range textTypes {
+		if t.PkgPath() == n[0] && t.Name() == n[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// parser returns the function the generated code parses the text of t with, when t is *url.URL,
+// *regexp.Regexp or *template.Template, and imports its package
+func (g *generator) parser(t types.Type) (string, bool) {
+	p, ok := t.(*types.Pointer)
+	switch {
+	case !ok:
+		return "", false
+	case isNamed(p.Elem(), "net/url", "URL"):
+		g.imports["net/url"] = "url"
+		return "url.Parse", true
+	case isNamed(p.Elem(), "regexp", "Regexp"):
+		g.imports["regexp"] = "regexp"
+		return "regexp.Compile", true
+	case isNamed(p.Elem(), "text/template", "Template"):
+		g.imports["text/template"] = "template"
+		return `template.New("").Parse`, true
+	}
+	return "", false
+}
+
+// isTextType is textType for go/types, for the value types read with UnmarshalText
+func isTextType(t types.Type) bool {
+	for _, n := range textTypes {
+		if isNamed(t, n[0], n[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNamed(t types.Type, pkg, name string) bool {
+	n, ok := types.Unalias(t).(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == pkg && n.Obj().Name() == name
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGeneratedIsFresh fails when the committed output of the test package is stale, run go generate ./... to fix it
+func TestGeneratedIsFresh(t *testing.T) {
+	src, dir, err := generate("", "./internal/gentest", "Config")
+	require.NoError(t, err)
+
+	abs, err := filepath.Abs("internal/gentest")
+	require.NoError(t, err)
+	assert.Equal(t, abs, dir)
+
+	committed, err := os.ReadFile(filepath.Join(dir, "config_clix.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), string(src))
+	// the generated code depends on the runtime package only, not on clix and its reflection
+	assert.NotContains(t, string(src), `"github.com/modfin/clix"`)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, _, err := generate("", "./testdata/bad", "Config")
+	assert.ErrorContains(t, err, `--port: invalid cli-default "eighty"`)
+
+	_, _, err = generate("", "./testdata/bad", "Renamed")
+	assert.ErrorContains(t, err, "--listen-addr: clixgen does not support aliases and deprecated names")
+
+	_, _, err = generate("", "./testdata/bad", "Listed")
+	assert.ErrorContains(t, err, "Upstreams: clixgen does not support slices of structs")
+
+	_, _, err = generate("", "./testdata/bad", "Mapped")
+	assert.ErrorContains(t, err, "Tenants: clixgen does not support maps of structs")
+
+	_, _, err = generate("", "./testdata/bad", "Union")
+	assert.ErrorContains(t, err, "Backend.Storage: clixgen does not support unions")
+
+	_, _, err = generate("", "./testdata/bad", "Copy")
+	assert.ErrorContains(t, err, "Src: clixgen does not support positional arguments")
+
+	_, _, err = generate("", "./testdata/bad", "Dated")
+	assert.ErrorContains(t, err, "--since: clixgen does not support cli-layout and cli-tz")
+
+	_, _, err = generate("", "./testdata/bad", "Pathed")
+	assert.ErrorContains(t, err, "--data: clixgen does not support cli-path")
+
+	_, _, err = generate("", "./testdata/bad", "Upstream")
+	assert.ErrorContains(t, err, "--url: clixgen does not support cli-scheme and cli-port")
+
+	_, _, err = generate("", "./internal/gentest", "Missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+// Package gentest holds a config struct and the code clixgen generates for it,
+// the tests check that the generated code behaves the same as clix.Parse and clixv3.Flags.
+package gentest
+
+import (
+	"net/netip"
+	"net/url"
+	"regexp"
+	"text/template"
+	"time"
+
+	"github.com/modfin/clix"
+)
+
+//go:generate go run github.com/modfin/clix/cmd/clixgen -type Config
+
+type Mode string
+
+type Level int
+
+// Common is embedded in Config, its port flag is shadowed by Config.Port
+type Common struct {
+	Verbose bool `cli:"verbose"`
+	Port    int  `cli:"port"`
+}
+
+type Config struct {
+	Common
+	Host     string             `cli:"host" cli-usage:"address to bind" cli-env:"HOST" cli-default:"localhost"`
+	Port     int                `cli:"port" cli-default:"8080"`
+	Mode     Mode               `cli:"mode" cli-default:"dev"`
+	Level    Level              `cli:"level"`
+	Workers  int32              `cli:"workers"`
+	MaxSize  int64              `cli:"max-size"`
+	Retries  uint               `cli:"retries" cli-default:"3"`
+	Window   uint32             `cli:"window"`
+	Limit    uint64             `cli:"limit"`
+	Debug    bool               `cli:"debug" cli-env:"DEBUG,APP_DEBUG"`
+	Ratio    float32            `cli:"ratio"`
+	Factor   float64            `cli:"factor" cli-default:"1.5"`
+	Timeout  time.Duration      `cli:"timeout" cli-default:"5s"`
+	Start    time.Time          `cli:"start" cli-default:"2024-01-02T03:04:05Z"`
+	Deadline *time.Time         `cli:"deadline"`
+	Tags     []string           `cli:"tags" cli-default:"a,b"`
+	IDs      []int              `cli:"ids"`
+	Offsets  []int64            `cli:"offsets"`
+	Counts   []uint             `cli:"counts"`
+	Sizes    []uint64           `cli:"sizes"`
+	Weights  []float64          `cli:"weights"`
+	Labels   map[string]string  `cli:"labels" cli-default:"env=dev"`
+	BodySize clix.ByteSize      `cli:"body-size" cli-default:"1MiB"`
+	Usage    clix.Percent       `cli:"usage"`
+	Limits   []clix.Rate        `cli:"limits" cli-default:"100/s"`
+	Listen   clix.HostPort      `cli:"listen" cli-default:":8080"`
+	Peer     netip.AddrPort     `cli:"peer"`
+	Allow    []netip.Prefix     `cli:"allow" cli-default:"10.0.0.0/8"`
+	Upstream *url.URL           `cli:"upstream" cli-default:"https://example.com"`
+	Mirrors  []*url.URL         `cli:"mirrors"`
+	Route    *regexp.Regexp     `cli:"route" cli-default:"^/api/"`
+	Ignore   []*regexp.Regexp   `cli:"ignore"`
+	Notify   *template.Template `cli:"notify"`
+	internal string
+
+	Database struct {
+		Host string `cli:"host" cli-default:"db"`
+		Port int    `cli:"port"`
+	} `cli-prefix:"db-"`
+}
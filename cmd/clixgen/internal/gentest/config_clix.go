@@ -0,0 +1,149 @@
+// Code generated by clixgen; DO NOT EDIT.
+
+package gentest
+
+import (
+	"net/url"
+	"regexp"
+	"text/template"
+	"time"
+
+	"github.com/modfin/clix/clixrt"
+	cli "github.com/urfave/cli/v3"
+)
+
+// ParseConfig populates a Config from the reader, it is the reflection-free equivalent of clix.Parse[Config](c).
+func ParseConfig(c clixrt.ContextReader) Config {
+	var cfg Config
+	cfg.Host = c.String("host")
+	cfg.Port = c.Int("port")
+	cfg.Mode = Mode(c.String("mode"))
+	cfg.Level = Level(c.Int("level"))
+	if r, ok := c.(clixrt.Int32Reader); ok {
+		cfg.Workers = r.Int32("workers")
+	} else {
+		cfg.Workers = int32(c.Int64("workers"))
+	}
+	cfg.MaxSize = c.Int64("max-size")
+	cfg.Retries = c.Uint("retries")
+	if r, ok := c.(clixrt.Uint32Reader); ok {
+		cfg.Window = r.Uint32("window")
+	} else {
+		cfg.Window = uint32(c.Uint64("window"))
+	}
+	cfg.Limit = c.Uint64("limit")
+	cfg.Debug = c.Bool("debug")
+	if r, ok := c.(clixrt.Float32Reader); ok {
+		cfg.Ratio = r.Float32("ratio")
+	} else {
+		cfg.Ratio = float32(c.Float64("ratio"))
+	}
+	cfg.Factor = c.Float64("factor")
+	cfg.Timeout = c.Duration("timeout")
+	if t := c.Timestamp("start"); t != nil {
+		cfg.Start = *t
+	}
+	if t := c.Timestamp("deadline"); t != nil {
+		cfg.Deadline = t
+	}
+	cfg.Tags = c.StringSlice("tags")
+	cfg.IDs = c.IntSlice("ids")
+	cfg.Offsets = c.Int64Slice("offsets")
+	cfg.Counts = c.UintSlice("counts")
+	cfg.Sizes = c.Uint64Slice("sizes")
+	cfg.Weights = c.Float64Slice("weights")
+	if r, ok := c.(clixrt.StringMapReader); ok {
+		cfg.Labels = r.StringMap("labels")
+	}
+	if s := c.String("body-size"); s != "" {
+		_ = cfg.BodySize.UnmarshalText([]byte(s))
+	}
+	if s := c.String("usage"); s != "" {
+		_ = cfg.Usage.UnmarshalText([]byte(s))
+	}
+	clixrt.ParseTexts(&cfg.Limits, c.StringSlice("limits"))
+	if s := c.String("listen"); s != "" {
+		_ = cfg.Listen.UnmarshalText([]byte(s))
+	}
+	if s := c.String("peer"); s != "" {
+		_ = cfg.Peer.UnmarshalText([]byte(s))
+	}
+	clixrt.ParseTexts(&cfg.Allow, c.StringSlice("allow"))
+	if s := c.String("upstream"); s != "" {
+		cfg.Upstream, _ = url.Parse(s)
+	}
+	if texts := c.StringSlice("mirrors"); texts != nil {
+		list := make([]*url.URL, len(texts))
+		for i, s := range texts {
+			var err error
+			if list[i], err = url.Parse(s); err != nil {
+				list = nil
+				break
+			}
+		}
+		cfg.Mirrors = list
+	}
+	if s := c.String("route"); s != "" {
+		cfg.Route, _ = regexp.Compile(s)
+	}
+	if texts := c.StringSlice("ignore"); texts != nil {
+		list := make([]*regexp.Regexp, len(texts))
+		for i, s := range texts {
+			var err error
+			if list[i], err = regexp.Compile(s); err != nil {
+				list = nil
+				break
+			}
+		}
+		cfg.Ignore = list
+	}
+	if s := c.String("notify"); s != "" {
+		cfg.Notify, _ = template.New("").Parse(s)
+	}
+	cfg.Common.Verbose = c.Bool("verbose")
+	cfg.Database.Host = c.String("db-host")
+	cfg.Database.Port = c.Int("db-port")
+	return cfg
+}
+
+// ConfigFlags returns the flags Config is parsed from, the same as clixv3.Flags[Config]().
+func ConfigFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "host", Usage: "address to bind", Sources: cli.EnvVars("HOST"), Value: "localhost"},
+		&cli.IntFlag{Name: "port", Value: 8080},
+		&cli.StringFlag{Name: "mode", Value: "dev"},
+		&cli.IntFlag{Name: "level"},
+		&cli.Int32Flag{Name: "workers"},
+		&cli.Int64Flag{Name: "max-size"},
+		&cli.UintFlag{Name: "retries", Value: 3},
+		&cli.Uint32Flag{Name: "window"},
+		&cli.Uint64Flag{Name: "limit"},
+		&cli.BoolFlag{Name: "debug", Sources: cli.EnvVars("DEBUG", "APP_DEBUG")},
+		&cli.Float32Flag{Name: "ratio"},
+		&cli.Float64Flag{Name: "factor", Value: 1.5},
+		&cli.DurationFlag{Name: "timeout", Value: time.Duration(5000000000)},
+		&cli.TimestampFlag{Name: "start", Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}, Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		&cli.TimestampFlag{Name: "deadline", Config: cli.TimestampConfig{Layouts: []string{time.RFC3339}}},
+		&cli.StringSliceFlag{Name: "tags", Value: []string{"a", "b"}},
+		&cli.IntSliceFlag{Name: "ids"},
+		&cli.Int64SliceFlag{Name: "offsets"},
+		&cli.UintSliceFlag{Name: "counts"},
+		&cli.Uint64SliceFlag{Name: "sizes"},
+		&cli.FloatSliceFlag{Name: "weights"},
+		&cli.StringMapFlag{Name: "labels", Value: map[string]string{"env": "dev"}},
+		&cli.StringFlag{Name: "body-size", Value: "1MiB"},
+		&cli.StringFlag{Name: "usage"},
+		&cli.StringSliceFlag{Name: "limits", Value: []string{"100/s"}},
+		&cli.StringFlag{Name: "listen", Value: ":8080"},
+		&cli.StringFlag{Name: "peer"},
+		&cli.StringSliceFlag{Name: "allow", Value: []string{"10.0.0.0/8"}},
+		&cli.StringFlag{Name: "upstream", Value: "https://example.com"},
+		&cli.StringSliceFlag{Name: "mirrors"},
+		&cli.StringFlag{Name: "route", Value: "^/api/"},
+		&cli.StringSliceFlag{Name: "ignore"},
+		&cli.StringFlag{Name: "notify"},
+		&cli.BoolFlag{Name: "verbose"},
+		&cli.StringFlag{Name: "db-host", Value: "db"},
+		&cli.IntFlag{Name: "db-port"},
+	}
+}
@@ -0,0 +1,97 @@
+package gentest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modfin/clix"
+	"github.com/modfin/clix/clixv3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestConfigFlags(t *testing.T) {
+	flags, err := clixv3.Flags[Config]()
+	require.NoError(t, err)
+	assert.Equal(t, flags, ConfigFlags())
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{name: "defaults"},
+		{name: "env", env: map[string]string{"HOST": "example.com", "APP_DEBUG": "true"}},
+		{
+			name: "all flags",
+			args: []string{
+				"--host", "0.0.0.0", "--port", "9090", "--mode", "prod", "--level", "3",
+				"--workers", "8", "--max-size", "1099511627776", "--retries", "5", "--window", "64", "--limit", "100",
+				"--debug", "--ratio", "0.25", "--factor", "2.5", "--timeout", "1m30s",
+				"--start", "2025-06-01T12:00:00Z", "--deadline", "2025-06-02T12:00:00+02:00",
+				"--tags", "x", "--tags", "y", "--ids", "1,2", "--offsets", "-1", "--counts", "7",
+				"--sizes", "9", "--weights", "0.5", "--labels", "team=core",
+				"--body-size", "1.5GB", "--usage", "85%", "--limits", "10/min", "--limits", "5/s",
+				"--listen", "127.0.0.1:9000", "--peer", "[::1]:7946", "--allow", "192.168.0.0/16",
+				"--upstream", "http://localhost:3000/api", "--mirrors", "https://a.example", "--mirrors", "https://b.example",
+				"--route", "^/v2/", "--ignore", `\.tmp$`, "--notify", "{{.Name}} is down",
+				"--db-host", "postgres", "--db-port", "5432",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var generated, reflected, fallback, reflectedFallback Config
+			cmd := &cli.Command{
+				Name:  "gentest",
+				Flags: ConfigFlags(),
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					generated = ParseConfig(clix.V3(cmd))
+					reflected = clix.Parse[Config](clix.V3(cmd))
+					// hide the optional reader interfaces to exercise the fallbacks
+					plain := struct{ clix.ContextReader }{clix.V3(cmd)}
+					fallback = ParseConfig(plain)
+					reflectedFallback = clix.Parse[Config](plain)
+					return nil
+				},
+			}
+			require.NoError(t, cmd.Run(context.Background(), append([]string{"gentest"}, tt.args...)))
+
+			assert.Equal(t, reflected, generated)
+			assert.Equal(t, reflectedFallback, fallback)
+		})
+	}
+}
+
+func TestParseConfigValues(t *testing.T) {
+	var cfg Config
+	cmd := &cli.Command{
+		Name:  "gentest",
+		Flags: ConfigFlags(),
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			cfg = ParseConfig(clix.V3(cmd))
+			return nil
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"gentest", "--mode", "prod", "--workers", "4"}))
+
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, Mode("prod"), cfg.Mode)
+	assert.Equal(t, int32(4), cfg.Workers)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Start)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, map[string]string{"env": "dev"}, cfg.Labels)
+	assert.Equal(t, "db", cfg.Database.Host)
+	assert.Equal(t, clix.ByteSize(1<<20), cfg.BodySize)
+	assert.Equal(t, []clix.Rate{{Count: 100, Per: time.Second}}, cfg.Limits)
+}
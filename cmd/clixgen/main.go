@@ -0,0 +1,53 @@
+// Command clixgen generates reflection-free equivalents of clix.Parse and clixv3.Flags for a config struct.
+// For a struct Config it writes
+//
+//	func ParseConfig(c clixrt.ContextReader) Config // same result as clix.Parse[Config](c)
+//	func ConfigFlags() []cli.Flag                   // same flags as clixv3.Flags[Config]()
+//
+// The struct is read from source with go/packages and the same tag rules as clix.Parse apply. The generated code
+// imports the runtime package clixrt instead of clix, clixrt.ContextReader is clix.ContextReader.
+//
+// clixgen reports an error instead of generating code for
+//
+//   - slices and maps of structs, unions and positional arguments
+//   - struct pointers holding flags
+//   - aliases and deprecated names, cli-layout and cli-tz, cli-path, cli-scheme and cli-port
+//
+// Usage:
+//
+//	//go:generate go run github.com/modfin/clix/cmd/clixgen -type Config
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var (
+		typeName = flag.String("type", "", "name of the config struct (required)")
+		pkg      = flag.String("pkg", ".", "package pattern containing the type")
+		out      = flag.String("o", "", "output file, defaults to <type>_clix.go in the package directory")
+	)
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, dir, err := generate("", *pkg, *typeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "clixgen:", err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		*out = dir + string(os.PathSeparator) + strings.ToLower(*typeName) + "_clix.go"
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "clixgen:", err)
+		os.Exit(1)
+	}
+}
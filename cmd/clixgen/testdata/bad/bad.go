@@ -0,0 +1,50 @@
+package bad
+
+import (
+	"net/url"
+	"time"
+)
+
+type Config struct {
+	Port int `cli:"port" cli-default:"eighty"`
+}
+
+type Renamed struct {
+	Addr string `cli:"listen-addr,addr"`
+}
+
+type Listed struct {
+	Upstreams []struct {
+		Host string `cli:"host"`
+	} `cli-prefix:"upstream-"`
+}
+
+type Mapped struct {
+	Tenants map[string]struct {
+		Host string `cli:"host"`
+	} `cli-prefix:"tenant-"`
+}
+
+type Storage interface{ Open() error }
+
+type Union struct {
+	Backend struct {
+		Storage Storage `cli-union:"storage"`
+	} `cli-prefix:"backend-"`
+}
+
+type Copy struct {
+	Src string `cli-arg:"0"`
+}
+
+type Dated struct {
+	Since time.Time `cli:"since" cli-layout:"DateOnly"`
+}
+
+type Pathed struct {
+	Data string `cli:"data" cli-path:"exists"`
+}
+
+type Upstream struct {
+	URL *url.URL `cli:"url" cli-scheme:"https"`
+}
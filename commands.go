@@ -0,0 +1,55 @@
+package clix
+
+import (
+	"context"
+	"reflect"
+)
+
+// Runner is implemented by the command structs of a struct-defined command tree.
+// parent is a pointer to the populated struct of the parent command, nil for the root.
+type Runner interface {
+	Run(ctx context.Context, parent any) error
+}
+
+// RunCommand runs a command of a struct-defined command tree, it is the part that does not depend on the cli framework.
+// The root struct and the command structs along path, the index paths of their fields from root, are populated
+// from the reader and validated as with TryParse, a failure is returned as a *UsageError. Each of the structs is
+// attached to the context, see FromContext, and Run is called on the last one with its parent struct.
+// clixv3.Command builds the urfave v3 command tree and calls it from the actions of its commands.
+func RunCommand(ctx context.Context, root reflect.Type, path [][]int, reader ContextReader, opts ...Option) error {
+	o := newOptions(opts)
+	cur := reflect.New(root).Elem()
+	chain := []reflect.Value{cur}
+	for _, index := range path {
+		field := cur.FieldByIndex(index)
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+		chain = append(chain, field)
+		cur = field
+	}
+
+	errs := &ParseError{}
+	for _, v := range chain {
+		if err := planFor(v.Type(), o).assign(v, "", reader, o); err != nil {
+			errs.add("", "", err)
+		}
+		if err := validateValue(v, reader, o); err != nil {
+			errs.add("", "", err)
+		}
+	}
+	if err := errs.orNil(); err != nil {
+		return &UsageError{Err: err}
+	}
+
+	for _, v := range chain {
+		ctx = withConfigValue(ctx, v)
+	}
+
+	var parent any
+	if len(chain) > 1 {
+		parent = chain[len(chain)-2].Addr().Interface()
+	}
+	return chain[len(chain)-1].Addr().Interface().(Runner).Run(ctx, parent)
+}
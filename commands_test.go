@@ -0,0 +1,61 @@
+package clix
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type toolCmd struct {
+	Verbose bool  `cli:"verbose"`
+	DB      dbCmd `cli-cmd:"db"`
+}
+
+type dbCmd struct {
+	DSN     string      `cli:"dsn" cli-required:"true"`
+	Migrate *migrateCmd `cli-cmd:"migrate"`
+}
+
+type migrateCmd struct {
+	Steps int `cli:"steps" cli-max:"10"`
+}
+
+// migrated records the last run of migrateCmd, with its parent and the root found in the context
+var migrated struct {
+	self   migrateCmd
+	parent dbCmd
+	root   toolCmd
+}
+
+func (m *migrateCmd) Run(ctx context.Context, parent any) error {
+	migrated.self, migrated.parent = *m, *parent.(*dbCmd)
+	migrated.root, _ = FromContext[toolCmd](ctx)
+	return nil
+}
+
+func TestRunCommand(t *testing.T) {
+	root := reflect.TypeOf(toolCmd{})
+	db, _ := root.FieldByName("DB")
+	migrate, _ := db.Type.FieldByName("Migrate")
+
+	ctx := newMockContext()
+	ctx.boolMap["verbose"] = true
+	ctx.stringMap["dsn"] = "postgres://"
+	ctx.intMap["steps"] = 3
+	require.NoError(t, RunCommand(context.Background(), root, [][]int{db.Index, migrate.Index}, ctx))
+	assert.Equal(t, migrateCmd{Steps: 3}, migrated.self)
+	assert.Equal(t, "postgres://", migrated.parent.DSN)
+	assert.True(t, migrated.root.Verbose)
+
+	ctx = newMockContext()
+	ctx.intMap["steps"] = 11
+	err := RunCommand(context.Background(), root, [][]int{db.Index, migrate.Index}, ctx)
+	var ue *UsageError
+	require.True(t, errors.As(err, &ue))
+	assert.Equal(t, UsageExitCode, ue.ExitCode())
+	assert.Equal(t, "Incorrect Usage: --dsn: required flag is not set; --steps: 11 is greater than 10", err.Error())
+}
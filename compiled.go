@@ -0,0 +1,65 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"weak"
+)
+
+// Compiled field types, *regexp.Regexp and *template.Template (text/template) are compiled when the config is
+// parsed and written back as their source text. Together with *url.URL they are the pointer types read from text.
+var (
+	regexpType   = reflect.TypeOf((*regexp.Regexp)(nil))
+	templateType = reflect.TypeOf((*template.Template)(nil))
+)
+
+// isPointerText reports whether t is one of the pointer types read from their text, a nil value has no text
+func isPointerText(t reflect.Type) bool {
+	return t == urlType || t == regexpType || t == templateType
+}
+
+// templateSources holds the source of the templates parseTemplate returned, keyed by a weak pointer to the
+// template, so that formatTemplate writes them back as given. An entry is dropped with its template.
+var templateSources sync.Map
+
+// parseTemplate parses s as a text/template, errors name the line of s, "invalid template, line 1: unclosed action"
+func parseTemplate(s string) (*template.Template, error) {
+	t, err := template.New("").Parse(s)
+	if err != nil {
+		if rest, ok := strings.CutPrefix(err.Error(), "template: :"); ok {
+			return nil, fmt.Errorf("invalid template, line %s", rest)
+		}
+		return nil, err
+	}
+	key := weak.Make(t)
+	templateSources.Store(key, s)
+	runtime.AddCleanup(t, func(key weak.Pointer[template.Template]) { templateSources.Delete(key) }, key)
+	return t, nil
+}
+
+// formatTemplate returns the source of t. Templates parsed by parseTemplate are returned as given, others are
+// written from their parse tree: the templates they define follow as {{define}} actions and spaces inside actions
+// are normalized, "{{ .Name }}" is written "{{.Name}}".
+func formatTemplate(t *template.Template) string {
+	if s, ok := templateSources.Load(weak.Make(t)); ok {
+		return s.(string)
+	}
+	var b strings.Builder
+	if t.Tree != nil {
+		b.WriteString(t.Tree.Root.String())
+	}
+	defined := t.Templates()
+	sort.Slice(defined, func(i, j int) bool { return defined[i].Name() < defined[j].Name() })
+	for _, d := range defined {
+		if d.Name() != t.Name() && d.Tree != nil {
+			fmt.Fprintf(&b, "{{define %q}}%s{{end}}", d.Name(), d.Tree.Root.String())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package clix
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Router struct {
+	Route  *regexp.Regexp       `cli:"route" cli-default:"^/api/"`
+	Ignore []*regexp.Regexp     `cli:"ignore"`
+	Notify *template.Template   `cli:"notify"`
+	Pages  []*template.Template `cli:"pages"`
+}
+
+func render(t *testing.T, tmpl *template.Template, data any) string {
+	var b strings.Builder
+	require.NoError(t, tmpl.Execute(&b, data))
+	return b.String()
+}
+
+func TestParseCompiled(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+route: ^/api/
+ignore: ['\.tmp$', '^/health']
+notify: '{{template "host" .}} is {{.Status | printf "%q"}}{{define "host"}}{{.Host}}{{end}}'
+pages: ['<h1>{{.}}</h1>']
+`))
+	require.NoError(t, err)
+
+	cfg, err := TryParse[Router](r)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Route)
+	assert.True(t, cfg.Route.MatchString("/api/users"))
+	require.Len(t, cfg.Ignore, 2)
+	assert.True(t, cfg.Ignore[0].MatchString("a.tmp"))
+	assert.Equal(t, `db is "down"`, render(t, cfg.Notify, map[string]string{"Host": "db", "Status": "down"}))
+	require.Len(t, cfg.Pages, 1)
+	assert.Equal(t, "<h1>Home</h1>", render(t, cfg.Pages[0], "Home"))
+
+	// empty and missing values are nil
+	r, err = NewFileReader([]byte(`route: ""`))
+	require.NoError(t, err)
+	cfg, err = TryParse[Router](r)
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Route)
+	assert.Nil(t, cfg.Notify)
+}
+
+func TestFormatTemplate(t *testing.T) {
+	// templates clix parsed are written back as given, spaces and comments included
+	source := `{{ .Host }} {{- /* port */ -}} :{{ template "port" . }}{{ define "port" }}{{ .Port }}{{ end }}`
+	r, err := NewFileReader([]byte("notify: '" + source + "'"))
+	require.NoError(t, err)
+	cfg, err := TryParse[Router](r)
+	require.NoError(t, err)
+	assert.Equal(t, source, formatTemplate(cfg.Notify))
+	args, err := ToArgs(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, args, "--notify="+source)
+
+	// others are written from their parse tree, which parses to the same template
+	tmpl := template.Must(template.New("").Parse(source))
+	formatted := formatTemplate(tmpl)
+	assert.Equal(t, `{{.Host}}:{{template "port" .}}{{define "port"}}{{.Port}}{{end}}`, formatted)
+	data := map[string]any{"Host": "db", "Port": 5432}
+	assert.Equal(t, render(t, tmpl, data), render(t, template.Must(template.New("").Parse(formatted)), data))
+}
+
+func TestParseCompiledErrors(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+route: '/users/(\d+'
+ignore: ['ok', '*.tmp']
+notify: '{{.Host'
+pages: ['{{end}}']
+`))
+	require.NoError(t, err)
+
+	_, err = TryParse[Router](r)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"--route: error parsing regexp: missing closing ): `/users/(\\d+`",
+		"--ignore: error parsing regexp: missing argument to repetition operator: `*`",
+		"--notify: invalid template, line 1: unclosed action",
+		"--pages: invalid template, line 1: unexpected {{end}}",
+	}, messages)
+}
+
+func TestCompileCompiledTagErrors(t *testing.T) {
+	type Config struct {
+		Route  *regexp.Regexp     `cli:"route" cli-default:"(["`
+		Notify *template.Template `cli:"notify" cli-default:"{{"`
+	}
+	_, err := Compile[Config]()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"--route: invalid cli-default \"([\": error parsing regexp: missing closing ]: `[`",
+		`--notify: invalid cli-default "{{": invalid template, line 1: unclosed action`,
+	}, messages)
+}
+
+func TestDescribeCompiled(t *testing.T) {
+	spec := Describe[Router]()
+	var types []string
+	for _, f := range spec.Flags {
+		types = append(types, f.Type)
+	}
+	assert.Equal(t, []string{"regexp", "[]regexp", "template", "[]template"}, types)
+	schema, err := JSONSchema[Router]()
+	require.NoError(t, err)
+	assert.Equal(t, "regex", schema.Properties["route"].Format)
+}
@@ -0,0 +1,28 @@
+package clix
+
+import (
+	"context"
+	"reflect"
+)
+
+// configKey is the context key of a config, one per config type
+type configKey struct {
+	t reflect.Type
+}
+
+// WithConfig returns a copy of ctx holding cfg, to be retrieved with FromContext[A].
+// Configs are keyed by type, so a context can hold one config of each type.
+func WithConfig[A any](ctx context.Context, cfg A) context.Context {
+	return context.WithValue(ctx, configKey{t: reflect.TypeOf((*A)(nil)).Elem()}, cfg)
+}
+
+// FromContext returns the config of type A stored in ctx by WithConfig or RunCommand, or by clixv3.Before.
+func FromContext[A any](ctx context.Context) (A, bool) {
+	cfg, ok := ctx.Value(configKey{t: reflect.TypeOf((*A)(nil)).Elem()}).(A)
+	return cfg, ok
+}
+
+// withConfigValue is WithConfig for a value only known through reflection
+func withConfigValue(ctx context.Context, v reflect.Value) context.Context {
+	return context.WithValue(ctx, configKey{t: v.Type()}, v.Interface())
+}
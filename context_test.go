@@ -0,0 +1,31 @@
+package clix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ActionConfig struct {
+	Name string `cli:"name" cli-required:"true"`
+	Port int    `cli:"port" cli-max:"65535"`
+}
+
+func TestWithConfig(t *testing.T) {
+	type Other struct{ Name string }
+
+	ctx := WithConfig(context.Background(), ActionConfig{Name: "app"})
+	ctx = WithConfig(ctx, &Other{Name: "ptr"})
+
+	cfg, ok := FromContext[ActionConfig](ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "app", cfg.Name)
+
+	other, ok := FromContext[*Other](ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "ptr", other.Name)
+
+	_, ok = FromContext[Other](ctx)
+	assert.False(t, ok)
+}
@@ -0,0 +1,103 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseText converts the text form of a flag value, as written in tags, env vars or config files, to a value of type t.
+// Slices are comma separated and maps are comma separated key=value pairs.
+// Types read from their text, ByteSize, Percent and Rate among them, are parsed with their UnmarshalText.
+func parseText(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	case reflect.TypeOf(time.Time{}):
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.ValueOf(ts))
+		return v, nil
+	}
+
+	if isTextType(t) {
+		return parseTextValue(t, s)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	case reflect.Ptr:
+		elem, err := parseText(t.Elem(), s)
+		if err != nil {
+			return v, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(elem)
+		v.Set(p)
+	case reflect.Slice:
+		parts := splitList(s)
+		v.Set(reflect.MakeSlice(t, len(parts), len(parts)))
+		for i, part := range parts {
+			elem, err := parseText(t.Elem(), part)
+			if err != nil {
+				return v, err
+			}
+			v.Index(i).Set(elem)
+		}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return v, fmt.Errorf("unsupported map type %s", t)
+		}
+		v.Set(reflect.MakeMap(t))
+		for _, pair := range splitList(s) {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return v, fmt.Errorf("invalid map entry %q, expected key=value", pair)
+			}
+			elem, err := parseText(t.Elem(), value)
+			if err != nil {
+				return v, err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+	default:
+		return v, fmt.Errorf("unsupported type %s", t)
+	}
+	return v, nil
+}
@@ -0,0 +1,45 @@
+package clix
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestCrossBuild vets the module for platforms without SIGHUP and inotify, clix and the code clixgen writes run on wasm
+func TestCrossBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("cross builds are slow")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not on the PATH")
+	}
+	for _, target := range []struct{ goos, goarch string }{{"js", "wasm"}, {"windows", "amd64"}} {
+		t.Run(target.goos, func(t *testing.T) {
+			cmd := exec.Command(goBin, "vet", "./...")
+			cmd.Env = append(os.Environ(), "GOOS="+target.goos, "GOARCH="+target.goarch)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("GOOS=%s GOARCH=%s go vet ./...: %v\n%s", target.goos, target.goarch, err, out)
+			}
+		})
+	}
+}
+
+// TestNoFrameworkImports checks that clix depends on no cli framework, those are left to the adapters clixv2 and clixv3
+func TestNoFrameworkImports(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not on the PATH")
+	}
+	out, err := exec.Command(goBin, "list", "-deps", ".").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go list -deps .: %v\n%s", err, out)
+	}
+	for _, pkg := range strings.Fields(string(out)) {
+		if strings.HasPrefix(pkg, "github.com/urfave/") {
+			t.Errorf("clix imports %s", pkg)
+		}
+	}
+}
@@ -0,0 +1,658 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlagSpec describes a single struct field that is bound to a CLI flag.
+// It is derived from the struct tags only and is used by the generators
+// (docs, man pages, schemas and templates) that need to know about the flags without parsing anything.
+type FlagSpec struct {
+	Name       string       // full flag name, prefix included
+	Key        string       // flag name without the inherited prefix, i.e. the first name of the "cli" tag
+	Aliases    []string     // full alternative names, from `cli:"name,alias"`
+	Deprecated []string     // full names still accepted with a warning, from `cli:"name|deprecated=old"`
+	Field      string       // Go field path, e.g. "Database.Host"
+	Index      []int        // reflect index path from the root struct
+	Type       string       // human-readable type, e.g. "int", "duration", "[]string"
+	GoType     reflect.Type // the Go type of the field
+	Env        []string     // environment variables, from `cli-env:"A,B"`
+	Default    string       // default value as text, from `cli-default`
+	Usage      string       // description, from `cli-usage`
+	Enum       []string     // allowed values, from `cli-oneof:"a,b,c"`
+	Required   bool         // from `cli-required:"true"`
+	Min        string       // lower bound, from `cli-min`
+	Max        string       // upper bound, from `cli-max`
+	Secret     bool         // value must not be shown, from `cli-secret:"true"`
+	Layouts    []string     // layouts of a time, from `cli-layout:"DateOnly|2006-01-02 15:04"`, RFC 3339 when empty
+	TZ         string       // time zone of times without one, from `cli-tz:"Europe/Stockholm"`
+	Path       []string     // path rewrites and checks, from `cli-path:"expand,abs,exists,dir"`
+	Schemes    []string     // allowed schemes of a URL, from `cli-scheme:"https,http"`
+	Port       string       // port of a HostPort given without one, from `cli-port:"5432"`
+}
+
+// SectionSpec is a group of flags, one per (nested) struct.
+// The root section has an empty Name.
+type SectionSpec struct {
+	Name     string // Go field name of the nested struct
+	Field    string // Go field path of the nested struct
+	Prefix   string // accumulated flag prefix
+	Usage    string // description, from `cli-usage` on the nested struct field
+	Embedded bool   // the struct is an embedded field, its flags are promoted to the parent
+	Flags    []FlagSpec
+	Sections []SectionSpec
+	Lists    []ListSpec
+	Maps     []MapSpec
+	Unions   []UnionSpec
+	Args     []ArgSpec
+}
+
+// ListSpec describes a slice of structs, Upstreams []Upstream `cli-prefix:"upstream-"`.
+// Its elements are read from indexed flags, upstream-0-host, upstream-1-host, or from a single flag
+// holding a JSON or YAML array, upstream.
+type ListSpec struct {
+	Name   string       // Go field name of the slice
+	Field  string       // Go field path of the slice
+	Index  []int        // reflect index path from the root struct
+	Prefix string       // accumulated prefix of the element flags, the element index follows it
+	Usage  string       // description, from `cli-usage`
+	GoType reflect.Type // the slice type
+}
+
+// FlagName returns the name of the flag holding the whole list as a JSON or YAML array,
+// the prefix without its trailing separator
+func (l ListSpec) FlagName() string {
+	return strings.TrimRight(l.Prefix, "-_.")
+}
+
+// ElemPrefix returns the prefix of the flags of the element at index i, e.g. "upstream-0-".
+// The index is followed by the separator the prefix ends with, "-" if it ends with none.
+func (l ListSpec) ElemPrefix(i int) string {
+	return l.elemPrefix(strconv.Itoa(i))
+}
+
+func (l ListSpec) elemPrefix(index string) string {
+	return elemPrefix(l.Prefix, index)
+}
+
+// MapSpec describes a map of structs keyed by instance name, Tenants map[string]Tenant `cli-prefix:"tenant-"`.
+// The flags of an instance follow the prefix and its name, tenant-acme-db-host. The names are the values of the flag
+// named by `cli-keys`, --tenants acme,globex, or when it is not given, found among the flag names the reader lists.
+// When neither names an instance, the names of `cli-default` are used, they are also the instances FlagDefs creates flags for.
+type MapSpec struct {
+	Name    string       // Go field name of the map
+	Field   string       // Go field path of the map
+	Index   []int        // reflect index path from the root struct
+	Prefix  string       // accumulated prefix of the instance flags, the instance name follows it
+	Keys    string       // full name of the flag listing the instance names, from `cli-keys`, empty if none
+	Default []string     // instance names used when none are given, from `cli-default:"acme,globex"`
+	Usage   string       // description, from `cli-usage`
+	GoType  reflect.Type // the map type
+
+	naming *NamePolicy
+}
+
+// ElemPrefix returns the prefix of the flags of the instance named key, e.g. "tenant-acme-".
+// The name is followed by the separator the prefix ends with, "-" if it ends with none.
+func (m MapSpec) ElemPrefix(key string) string {
+	return elemPrefix(m.Prefix, key)
+}
+
+// ElemSpec describes the flags of the instance named key, their names include its prefix, tenant-acme-db-host
+func (m MapSpec) ElemSpec(key string) SectionSpec {
+	return prefixSpec(describeType(indirect(m.GoType.Elem()), m.naming), m.ElemPrefix(key))
+}
+
+// ArgSpec describes a field bound to a positional argument, `cli-arg:"0"`, or to the remaining arguments, `cli-args:"rest"`.
+// The rules of the field, `cli-default`, `cli-oneof`, `cli-required`, `cli-min` and `cli-max`, apply as for flags.
+type ArgSpec struct {
+	Name     string       // name shown in usage, from `cli-arg:"0,name"` or the field name in kebab case
+	Position int          // index of the argument, -1 for the remaining arguments
+	Field    string       // Go field path
+	Index    []int        // reflect index path from the root struct
+	Type     string       // human-readable type, as FlagSpec.Type, of the elements for the remaining arguments
+	GoType   reflect.Type // the Go type of the field
+	Default  string       // from `cli-default`
+	Usage    string       // from `cli-usage`
+	Enum     []string     // from `cli-oneof`
+	Required bool         // from `cli-required:"true"`
+	Min      string       // from `cli-min`
+	Max      string       // from `cli-max`
+	Layouts  []string     // from `cli-layout`
+	TZ       string       // from `cli-tz`
+	Path     []string     // from `cli-path`
+	Schemes  []string     // from `cli-scheme`
+	Port     string       // from `cli-port`
+}
+
+// UnionSpec describes an interface field holding one of the variants registered with RegisterVariant,
+// Storage Storage `cli-union:"storage"`. The discriminator flag, --storage s3, selects the variant
+// and only the flags of that variant are read, --s3-bucket.
+type UnionSpec struct {
+	Name     string       // Go field name of the interface
+	Field    string       // Go field path of the interface
+	Index    []int        // reflect index path from the root struct
+	Flag     string       // full name of the discriminator flag, from `cli-union`
+	Prefix   string       // accumulated prefix of the variant flags, the variant name follows it
+	Env      []string     // environment variables of the discriminator, from `cli-env`
+	Default  string       // default variant, from `cli-default`
+	Usage    string       // description, from `cli-usage`
+	Required bool         // from `cli-required:"true"`
+	GoType   reflect.Type // the interface type
+	Variants []VariantSpec
+}
+
+// VariantSpec is one of the variants of a union
+type VariantSpec struct {
+	Name   string       // value of the discriminator selecting the variant
+	Prefix string       // full prefix of the flags of the variant, e.g. "s3-"
+	GoType reflect.Type // the type stored in the interface, a struct or a pointer to one
+
+	naming *NamePolicy
+}
+
+// Names returns the names of the variants, in the order they were registered
+func (u UnionSpec) Names() []string {
+	names := make([]string, len(u.Variants))
+	for i, v := range u.Variants {
+		names[i] = v.Name
+	}
+	return names
+}
+
+// Variant returns the variant with the given name
+func (u UnionSpec) Variant(name string) (VariantSpec, bool) {
+	for _, v := range u.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return VariantSpec{}, false
+}
+
+// Spec describes the flags of the variant, their names include the prefix of the variant
+func (v VariantSpec) Spec() SectionSpec {
+	return prefixSpec(describeType(v.GoType, v.naming), v.Prefix)
+}
+
+func elemPrefix(prefix, key string) string {
+	sep := "-"
+	if n := len(prefix); n > 0 && strings.ContainsAny(prefix[n-1:], "-_.") {
+		sep = prefix[n-1:]
+	}
+	return prefix + key + sep
+}
+
+// Describe walks the struct A the same way Parse does and returns the flags it would read.
+// Of the options only WithAutoNames has an effect.
+func Describe[A any](opts ...Option) SectionSpec {
+	return DescribeType(reflect.TypeOf((*A)(nil)).Elem(), opts...)
+}
+
+// DescribeType is the non-generic version of Describe.
+// t must be a struct type or a pointer to one.
+func DescribeType(t reflect.Type, opts ...Option) SectionSpec {
+	return describeType(t, newOptions(opts).naming)
+}
+
+func describeType(t reflect.Type, naming *NamePolicy) SectionSpec {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var root SectionSpec
+	describeStruct(t, "", "", nil, naming, &root)
+	return root
+}
+
+// Names returns the full name, the aliases and the deprecated names of the flag, in the order Parse tries them.
+func (f FlagSpec) Names() []string {
+	return append(append([]string{f.Name}, f.Aliases...), f.Deprecated...)
+}
+
+// Walk calls fn for every flag in the section and its subsections, depth first.
+func (s SectionSpec) Walk(fn func(sec SectionSpec, f FlagSpec)) {
+	for _, f := range s.Flags {
+		fn(s, f)
+	}
+	for _, sub := range s.Sections {
+		sub.Walk(fn)
+	}
+}
+
+// WalkLists calls fn for every list in the section and its subsections, depth first.
+// The flags of the list elements are not part of the section, see ListSpec.
+func (s SectionSpec) WalkLists(fn func(sec SectionSpec, l ListSpec)) {
+	for _, l := range s.Lists {
+		fn(s, l)
+	}
+	for _, sub := range s.Sections {
+		sub.WalkLists(fn)
+	}
+}
+
+// WalkMaps calls fn for every map in the section and its subsections, depth first.
+// The flags of the map instances are not part of the section, see MapSpec.
+func (s SectionSpec) WalkMaps(fn func(sec SectionSpec, m MapSpec)) {
+	for _, m := range s.Maps {
+		fn(s, m)
+	}
+	for _, sub := range s.Sections {
+		sub.WalkMaps(fn)
+	}
+}
+
+// WalkUnions calls fn for every union in the section and its subsections, depth first.
+// The flags of the variants are not part of the section, see UnionSpec.
+func (s SectionSpec) WalkUnions(fn func(sec SectionSpec, u UnionSpec)) {
+	for _, u := range s.Unions {
+		fn(s, u)
+	}
+	for _, sub := range s.Sections {
+		sub.WalkUnions(fn)
+	}
+}
+
+// WalkArgs calls fn for every positional argument in the section and its subsections, depth first.
+func (s SectionSpec) WalkArgs(fn func(sec SectionSpec, a ArgSpec)) {
+	for _, a := range s.Args {
+		fn(s, a)
+	}
+	for _, sub := range s.Sections {
+		sub.WalkArgs(fn)
+	}
+}
+
+// IsEmpty reports whether the section, including its subsections, holds no flags, lists, maps, unions or arguments.
+func (s SectionSpec) IsEmpty() bool {
+	empty := true
+	s.WalkArgs(func(SectionSpec, ArgSpec) { empty = false })
+	s.Walk(func(SectionSpec, FlagSpec) { empty = false })
+	s.WalkLists(func(SectionSpec, ListSpec) { empty = false })
+	s.WalkMaps(func(SectionSpec, MapSpec) { empty = false })
+	s.WalkUnions(func(SectionSpec, UnionSpec) { empty = false })
+	return empty
+}
+
+func describeStruct(t reflect.Type, prefix, path string, index []int, naming *NamePolicy, sec *SectionSpec) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		// Commands are described by clixv3.Command, not as flags of their parent
+		if !fieldType.IsExported() || fieldType.Tag.Get("cli-cmd") != "" {
+			continue
+		}
+
+		fieldPath := joinPath(path, fieldType.Name)
+		fieldIndex := append(append([]int{}, index...), i)
+		tag := fieldType.Tag.Get("cli")
+		if tag == "-" {
+			continue
+		}
+
+		if a, ok := describeArg(fieldType); ok && tag == "" {
+			a.Field, a.Index = fieldPath, fieldIndex
+			sec.Args = append(sec.Args, a)
+			continue
+		}
+		if st, ok := sectionType(fieldType.Type); ok && tag == "" {
+			sectionPrefix, ok := fieldType.Tag.Lookup("cli-prefix")
+			if !ok && naming != nil && !fieldType.Anonymous {
+				sectionPrefix = naming.Prefix(fieldType.Name)
+			}
+			sub := SectionSpec{
+				Name:     fieldType.Name,
+				Field:    fieldPath,
+				Prefix:   prefix + sectionPrefix,
+				Usage:    fieldType.Tag.Get("cli-usage"),
+				Embedded: fieldType.Anonymous,
+			}
+			describeStruct(st, sub.Prefix, fieldPath, fieldIndex, naming, &sub)
+			if !sub.IsEmpty() {
+				sec.Sections = append(sec.Sections, sub)
+			}
+			continue
+		}
+		if _, ok := listType(fieldType.Type); ok && tag == "" {
+			listPrefix, ok := fieldType.Tag.Lookup("cli-prefix")
+			if !ok && naming != nil {
+				listPrefix = naming.Prefix(fieldType.Name)
+			}
+			if listPrefix != "" {
+				sec.Lists = append(sec.Lists, ListSpec{
+					Name:   fieldType.Name,
+					Field:  fieldPath,
+					Index:  fieldIndex,
+					Prefix: prefix + listPrefix,
+					Usage:  fieldType.Tag.Get("cli-usage"),
+					GoType: fieldType.Type,
+				})
+			}
+			continue
+		}
+		if _, ok := mapType(fieldType.Type); ok && tag == "" {
+			mapPrefix, ok := fieldType.Tag.Lookup("cli-prefix")
+			if !ok && naming != nil {
+				mapPrefix = naming.Prefix(fieldType.Name)
+			}
+			if mapPrefix != "" {
+				var keys string
+				if k := fieldType.Tag.Get("cli-keys"); k != "" {
+					keys = prefix + k
+				}
+				sec.Maps = append(sec.Maps, MapSpec{
+					Name:    fieldType.Name,
+					Field:   fieldPath,
+					Index:   fieldIndex,
+					Prefix:  prefix + mapPrefix,
+					Keys:    keys,
+					Default: splitList(fieldType.Tag.Get("cli-default")),
+					Usage:   fieldType.Tag.Get("cli-usage"),
+					GoType:  fieldType.Type,
+					naming:  naming,
+				})
+			}
+			continue
+		}
+		if disc := fieldType.Tag.Get("cli-union"); disc != "" && tag == "" && fieldType.Type.Kind() == reflect.Interface {
+			u := UnionSpec{
+				Name:     fieldType.Name,
+				Field:    fieldPath,
+				Index:    fieldIndex,
+				Flag:     prefix + disc,
+				Prefix:   prefix + fieldType.Tag.Get("cli-prefix"),
+				Env:      splitList(fieldType.Tag.Get("cli-env")),
+				Default:  fieldType.Tag.Get("cli-default"),
+				Usage:    fieldType.Tag.Get("cli-usage"),
+				Required: fieldType.Tag.Get("cli-required") == "true",
+				GoType:   fieldType.Type,
+			}
+			for _, v := range variantsOf(fieldType.Type) {
+				u.Variants = append(u.Variants, VariantSpec{Name: v.name, Prefix: elemPrefix(u.Prefix, v.name), GoType: v.typ, naming: naming})
+			}
+			sec.Unions = append(sec.Unions, u)
+			continue
+		}
+		if tag == "" && naming != nil {
+			// fields of types no flag can set, such as funcs, are left unnamed
+			if compileSetter(fieldType.Type) == nil {
+				continue
+			}
+			tag = naming.Name(fieldType.Name)
+		}
+		if tag == "" {
+			continue
+		}
+
+		names, _ := parseNameTag(tag)
+		sec.Flags = append(sec.Flags, FlagSpec{
+			Name:       prefix + names.name,
+			Key:        names.name,
+			Aliases:    prefixNames(prefix, names.aliases),
+			Deprecated: prefixNames(prefix, names.deprecated),
+			Field:      fieldPath,
+			Index:      fieldIndex,
+			Type:       typeName(fieldType.Type),
+			GoType:     fieldType.Type,
+			Env:        splitList(fieldType.Tag.Get("cli-env")),
+			Default:    fieldType.Tag.Get("cli-default"),
+			Usage:      fieldType.Tag.Get("cli-usage"),
+			Enum:       splitList(fieldType.Tag.Get("cli-oneof")),
+			Required:   fieldType.Tag.Get("cli-required") == "true",
+			Min:        fieldType.Tag.Get("cli-min"),
+			Max:        fieldType.Tag.Get("cli-max"),
+			Secret:     fieldType.Tag.Get("cli-secret") == "true",
+			Layouts:    splitLayouts(fieldType.Tag.Get("cli-layout")),
+			TZ:         fieldType.Tag.Get("cli-tz"),
+			Path:       splitList(fieldType.Tag.Get("cli-path")),
+			Schemes:    splitList(fieldType.Tag.Get("cli-scheme")),
+			Port:       fieldType.Tag.Get("cli-port"),
+		})
+	}
+	shadowPromoted(sec)
+}
+
+// sectionType returns the struct type of a field that is read as a section, a struct or a pointer to one, time.Time and text types excluded
+func sectionType(t reflect.Type) (reflect.Type, bool) {
+	if isTextType(t) {
+		return t, false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) && !isTextType(t)
+}
+
+// listType returns the element type of a slice of structs, time.Time excluded
+func listType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Slice {
+		return nil, false
+	}
+	return sectionType(t.Elem())
+}
+
+func isList(t reflect.Type) bool {
+	_, ok := listType(t)
+	return ok
+}
+
+// mapType returns the value type of a map of structs with string keys, time.Time excluded
+func mapType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return nil, false
+	}
+	return sectionType(t.Elem())
+}
+
+func isMap(t reflect.Type) bool {
+	_, ok := mapType(t)
+	return ok
+}
+
+// shadowPromoted drops the flags of embedded sections that are also declared by the section's own fields,
+// the way a field of a struct hides a promoted field of the same name
+func shadowPromoted(sec *SectionSpec) {
+	own := map[string]bool{}
+	for _, f := range sec.Flags {
+		for _, n := range f.Names() {
+			own[n] = true
+		}
+	}
+	if len(own) == 0 {
+		return
+	}
+	sections := sec.Sections[:0]
+	for _, sub := range sec.Sections {
+		if sub.Embedded {
+			sub = dropFlags(sub, own)
+		}
+		if !sub.IsEmpty() {
+			sections = append(sections, sub)
+		}
+	}
+	sec.Sections = sections
+}
+
+// dropFlags returns a copy of sec without the flags having one of the names
+func dropFlags(sec SectionSpec, names map[string]bool) SectionSpec {
+	var flags []FlagSpec
+	for _, f := range sec.Flags {
+		if !names[f.Name] {
+			flags = append(flags, f)
+		}
+	}
+	sec.Flags = flags
+	var sections []SectionSpec
+	for _, sub := range sec.Sections {
+		if sub = dropFlags(sub, names); !sub.IsEmpty() {
+			sections = append(sections, sub)
+		}
+	}
+	sec.Sections = sections
+	return sec
+}
+
+// collisions reports flag names promoted by more than one embedded struct of the same section
+func collisions(sec SectionSpec, errs *ParseError) {
+	owner := map[string]string{}
+	for _, sub := range sec.Sections {
+		if !sub.Embedded {
+			continue
+		}
+		seen := map[string]bool{}
+		sub.Walk(func(_ SectionSpec, f FlagSpec) {
+			for _, n := range f.Names() {
+				if seen[n] {
+					continue
+				}
+				seen[n] = true
+				if other, ok := owner[n]; ok {
+					errs.add(n, f.Field, fmt.Errorf("flag is promoted from both %s and %s", other, sub.Field))
+					continue
+				}
+				owner[n] = sub.Field
+			}
+		})
+	}
+	for _, sub := range sec.Sections {
+		collisions(sub, errs)
+	}
+}
+
+// prefixSpec returns a copy of sec with prefix prepended to its flag names and the prefixes of its lists, maps and unions
+func prefixSpec(sec SectionSpec, prefix string) SectionSpec {
+	flags := make([]FlagSpec, len(sec.Flags))
+	for i, f := range sec.Flags {
+		f.Name = prefix + f.Name
+		f.Aliases = prefixNames(prefix, f.Aliases)
+		f.Deprecated = prefixNames(prefix, f.Deprecated)
+		flags[i] = f
+	}
+	sec.Flags = flags
+	lists := make([]ListSpec, len(sec.Lists))
+	for i, l := range sec.Lists {
+		l.Prefix = prefix + l.Prefix
+		lists[i] = l
+	}
+	sec.Lists = lists
+	maps := make([]MapSpec, len(sec.Maps))
+	for i, m := range sec.Maps {
+		m.Prefix = prefix + m.Prefix
+		if m.Keys != "" {
+			m.Keys = prefix + m.Keys
+		}
+		maps[i] = m
+	}
+	sec.Maps = maps
+	unions := make([]UnionSpec, len(sec.Unions))
+	for i, u := range sec.Unions {
+		u.Flag = prefix + u.Flag
+		u.Prefix = prefix + u.Prefix
+		variants := make([]VariantSpec, len(u.Variants))
+		for j, v := range u.Variants {
+			v.Prefix = prefix + v.Prefix
+			variants[j] = v
+		}
+		u.Variants = variants
+		unions[i] = u
+	}
+	sec.Unions = unions
+	sections := make([]SectionSpec, len(sec.Sections))
+	for i, sub := range sec.Sections {
+		sub.Prefix = prefix + sub.Prefix
+		sections[i] = prefixSpec(sub, prefix)
+	}
+	sec.Sections = sections
+	return sec
+}
+
+// nameTag is the parsed `cli` tag, `cli:"name,alias|deprecated=old"`
+type nameTag struct {
+	name       string
+	aliases    []string
+	deprecated []string
+}
+
+// parseNameTag parses a `cli` tag. The comma separated names before the first "|" are the name and its aliases,
+// each "|" is followed by an option, "deprecated=a,b" being the only one.
+func parseNameTag(tag string) (nameTag, error) {
+	parts := strings.Split(tag, "|")
+	names := splitList(parts[0])
+	if len(names) == 0 {
+		return nameTag{}, fmt.Errorf("no flag name in cli tag %q", tag)
+	}
+	nt := nameTag{name: names[0], aliases: names[1:]}
+	for _, opt := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "deprecated":
+			nt.deprecated = append(nt.deprecated, splitList(value)...)
+		default:
+			return nt, fmt.Errorf("unknown option %q in cli tag %q", key, tag)
+		}
+	}
+	return nt, nil
+}
+
+func prefixNames(prefix string, names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = prefix + n
+	}
+	return out
+}
+
+// typeName returns the name used for t in generated documentation.
+func typeName(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case reflect.TypeOf(time.Time{}), reflect.PointerTo(reflect.TypeOf(time.Time{})):
+		return "timestamp"
+	}
+	if isTextType(t) {
+		// ByteSize is "bytesize", netip.AddrPort "addrport" and *url.URL "url"
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		return strings.ToLower(t.Name())
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	case reflect.Ptr:
+		return typeName(t.Elem())
+	}
+	return t.Kind().String()
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// splitList splits a comma separated tag value, trimming blanks and dropping empty entries.
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var list []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
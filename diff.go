@@ -0,0 +1,228 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Redacted replaces the values of `cli-secret:"true"` fields in a Change
+const Redacted = "[redacted]"
+
+// Change is a flag whose value differs between two configs
+type Change struct {
+	Flag  string // full flag name
+	Field string // Go field path
+	Old   any    // value in the first config, nil for a nil pointer
+	New   any    // value in the second config, nil for a nil pointer
+}
+
+// Diff compares two configs flag by flag and returns the flags whose values differ, in the order of Describe.
+// Pointers are compared by the value they point to, times with time.Time.Equal, and nil and empty slices or maps are equal.
+// Values of fields tagged `cli-secret:"true"` are replaced by Redacted unless they are zero.
+// Slices and maps of structs are compared element by element, e.g. --upstream-1-host or --tenant-acme-db-host,
+// and unions by their discriminator and the flags of their variants.
+// The options are those the configs were parsed with, only WithAutoNames matters.
+// Usage:
+//
+//	w.Subscribe(func(old, new Config) {
+//		log.Print("config reloaded\n", clix.FormatDiff(clix.Diff(old, new)))
+//	})
+func Diff[A any](a, b A, opts ...Option) []Change {
+	var changes []Change
+	diffStruct(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem(), "", "", newOptions(opts), &changes)
+	return changes
+}
+
+// diffStruct appends the changes between a and b, whose flags are read with prefix and fields found under path.
+// Slices and maps of structs are compared element by element, an element only one of the configs holds
+// is compared with the zero value.
+func diffStruct(a, b reflect.Value, prefix, path string, o *options, changes *[]Change) {
+	p := planFor(a.Type(), o)
+	p.spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		old := fieldByIndexOrZero(a, f.Index, f.GoType)
+		cur := fieldByIndexOrZero(b, f.Index, f.GoType)
+		if equalValues(old, cur) {
+			return
+		}
+		*changes = append(*changes, Change{
+			Flag:  prefix + f.Name,
+			Field: joinPath(path, f.Field),
+			Old:   changeValue(old, f.Secret),
+			New:   changeValue(cur, f.Secret),
+		})
+	})
+
+	for _, l := range p.lists {
+		la := fieldByIndexOrZero(a, l.Index, l.GoType)
+		lb := fieldByIndexOrZero(b, l.Index, l.GoType)
+		for i := 0; i < max(la.Len(), lb.Len()); i++ {
+			diffStruct(listElemOrZero(la, i), listElemOrZero(lb, i), prefix+l.ElemPrefix(i),
+				fmt.Sprintf("%s[%d]", joinPath(path, l.Field), i), o, changes)
+		}
+	}
+	for _, m := range p.maps {
+		ma := fieldByIndexOrZero(a, m.Index, m.GoType)
+		mb := fieldByIndexOrZero(b, m.Index, m.GoType)
+		keys := map[string]reflect.Value{}
+		for _, key := range append(ma.MapKeys(), mb.MapKeys()...) {
+			keys[key.String()] = key
+		}
+		for _, key := range sortedKeys(reflect.ValueOf(keys)) {
+			k := keys[key.String()]
+			diffStruct(mapElemOrZero(ma, k), mapElemOrZero(mb, k), prefix+m.ElemPrefix(k.String()),
+				fmt.Sprintf("%s[%s]", joinPath(path, m.Field), k.String()), o, changes)
+		}
+	}
+	for _, u := range p.unions {
+		va, ea, okA := unionValue(u, fieldByIndexOrZero(a, u.Index, u.GoType))
+		vb, eb, okB := unionValue(u, fieldByIndexOrZero(b, u.Index, u.GoType))
+		if va.Name != vb.Name {
+			*changes = append(*changes, Change{Flag: prefix + u.Flag, Field: joinPath(path, u.Field), Old: va.Name, New: vb.Name})
+		}
+		field := joinPath(path, u.Field)
+		switch {
+		case okA && okB && va.Name == vb.Name:
+			diffStruct(ea, eb, prefix+va.Prefix, field, o, changes)
+		default:
+			// a variant that is dropped or picked up is compared with its zero value
+			if okA {
+				diffStruct(ea, reflect.Zero(ea.Type()), prefix+va.Prefix, field, o, changes)
+			}
+			if okB {
+				diffStruct(reflect.Zero(eb.Type()), eb, prefix+vb.Prefix, field, o, changes)
+			}
+		}
+	}
+}
+
+// mapElemOrZero returns the struct held by m under key, or the zero struct when there is none
+func mapElemOrZero(m, key reflect.Value) reflect.Value {
+	t := indirect(m.Type().Elem())
+	elem := m.MapIndex(key)
+	if !elem.IsValid() {
+		return reflect.Zero(t)
+	}
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return reflect.Zero(t)
+		}
+		elem = elem.Elem()
+	}
+	return elem
+}
+
+// listElemOrZero returns the struct of the element i of list, or the zero struct when there is none
+func listElemOrZero(list reflect.Value, i int) reflect.Value {
+	t := indirect(list.Type().Elem())
+	if i >= list.Len() {
+		return reflect.Zero(t)
+	}
+	elem := list.Index(i)
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return reflect.Zero(t)
+		}
+		elem = elem.Elem()
+	}
+	return elem
+}
+
+// Defaults returns A with every field set to its `cli-default`, the config Parse would return when no flag is given.
+// It is handy to Diff against:
+//
+//	changes := clix.Diff(clix.Defaults[Config](), cfg)
+func Defaults[A any](opts ...Option) A {
+	var cfg A
+	val := reflect.ValueOf(&cfg).Elem()
+	planFor(val.Type(), newOptions(opts)).spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		if f.Default == "" {
+			return
+		}
+		if def, err := parseTagValue(f, f.Default); err == nil {
+			fieldByIndexAlloc(val, f.Index).Set(def)
+		}
+	})
+	return cfg
+}
+
+func equalValues(a, b reflect.Value) bool {
+	if isPointerText(elemType(a.Type())) {
+		// URLs, regexps and templates are equal when their text is
+		ta, _ := flagTexts(a, FlagSpec{})
+		tb, _ := flagTexts(b, FlagSpec{})
+		return slices.Equal(ta, tb)
+	}
+	if a.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equalValues(a.Elem(), b.Elem())
+	}
+	if a.Type() == reflect.TypeOf(time.Time{}) {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+	switch a.Kind() {
+	case reflect.Slice, reflect.Map:
+		if a.Len() == 0 || b.Len() == 0 {
+			return a.Len() == b.Len()
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func changeValue(v reflect.Value, secret bool) any {
+	if isPointerText(elemType(v.Type())) && !secret {
+		// URLs, regexps and templates are shown as their text
+		switch {
+		case v.Kind() == reflect.Slice:
+			texts, _ := flagTexts(v, FlagSpec{})
+			return texts
+		case !v.IsNil():
+			text, _ := formatText(v)
+			return text
+		}
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if secret && !v.IsZero() {
+		return Redacted
+	}
+	return v.Interface()
+}
+
+// FormatDiff renders changes as an aligned table with a FLAG, OLD and NEW column
+func FormatDiff(changes []Change) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tOLD\tNEW")
+	for _, c := range changes {
+		fmt.Fprintf(tw, "--%s\t%s\t%s\n", c.Flag, formatChangeValue(c.Old), formatChangeValue(c.New))
+	}
+	tw.Flush()
+	return b.String()
+}
+
+func formatChangeValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		if v == Redacted {
+			return v
+		}
+		return fmt.Sprintf("%q", v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,88 @@
+package clix
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type DiffConfig struct {
+	Host     string            `cli:"host"`
+	Password string            `cli:"password" cli-secret:"true"`
+	Timeout  time.Duration     `cli:"timeout"`
+	Start    time.Time         `cli:"start"`
+	Deadline *time.Time        `cli:"deadline"`
+	Tags     []string          `cli:"tags"`
+	Labels   map[string]string `cli:"labels"`
+	DB       struct {
+		Name string `cli:"name"`
+	} `cli-prefix:"db-"`
+}
+
+func TestDiff(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	deadline := start.Add(time.Hour)
+
+	a := DiffConfig{Host: "a", Password: "old", Timeout: time.Second, Start: start, Tags: []string{}, Labels: map[string]string{"env": "dev"}}
+	b := a
+	b.Host = "b"
+	b.Password = "new"
+	b.Start = start.In(time.FixedZone("CET", 3600)) // same instant
+	b.Deadline = &deadline
+	b.Tags = nil // nil and empty are equal
+	b.Labels = map[string]string{"env": "prod"}
+	b.DB.Name = "app"
+
+	assert.Equal(t, []Change{
+		{Flag: "host", Field: "Host", Old: "a", New: "b"},
+		{Flag: "password", Field: "Password", Old: Redacted, New: Redacted},
+		{Flag: "deadline", Field: "Deadline", Old: nil, New: deadline},
+		{Flag: "labels", Field: "Labels", Old: map[string]string{"env": "dev"}, New: map[string]string{"env": "prod"}},
+		{Flag: "db-name", Field: "DB.Name", Old: "", New: "app"},
+	}, Diff(a, b))
+
+	assert.Empty(t, Diff(a, a))
+
+	later := deadline.Add(time.Minute)
+	c := b
+	c.Deadline = &later
+	c.Password = ""
+	assert.Equal(t, []Change{
+		{Flag: "password", Field: "Password", Old: Redacted, New: ""},
+		{Flag: "deadline", Field: "Deadline", Old: deadline, New: later},
+	}, Diff(b, c))
+}
+
+func TestFormatDiff(t *testing.T) {
+	changes := []Change{
+		{Flag: "host", Old: "a", New: "example.com"},
+		{Flag: "password", Old: Redacted, New: Redacted},
+		{Flag: "timeout", Old: time.Second, New: 90 * time.Second},
+		{Flag: "deadline", Old: nil, New: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Flag: "tags", Old: []string{"a"}, New: []string{"a", "b"}},
+	}
+
+	assert.Equal(t, `FLAG        OLD         NEW
+--host      "a"         "example.com"
+--password  [redacted]  [redacted]
+--timeout   1s          1m30s
+--deadline  <nil>       2024-01-02T03:04:05Z
+--tags      [a]         [a b]
+`, FormatDiff(changes))
+}
+
+func TestDiffFromDefaults(t *testing.T) {
+	type Config struct {
+		Host    string        `cli:"host" cli-default:"localhost"`
+		Port    int           `cli:"port" cli-default:"8080"`
+		Timeout time.Duration `cli:"timeout" cli-default:"5s"`
+	}
+
+	defaults := Defaults[Config]()
+	assert.Equal(t, Config{Host: "localhost", Port: 8080, Timeout: 5 * time.Second}, defaults)
+
+	cfg := defaults
+	cfg.Port = 9090
+	assert.Equal(t, []Change{{Flag: "port", Field: "Port", Old: 8080, New: 9090}}, Diff(defaults, cfg))
+}
@@ -0,0 +1,226 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DocsOptions controls the output of DocsMarkdown.
+type DocsOptions struct {
+	// Title is rendered as the top heading, it is omitted when empty
+	Title string
+	// Description is rendered as a paragraph below the title
+	Description string
+	// HeadingLevel is the markdown level of the top heading, defaults to 1
+	HeadingLevel int
+}
+
+// DocsMarkdown renders a markdown reference of every flag that Parse reads into A.
+// Each nested struct becomes its own heading with a table listing the flag, env vars, type, default and description.
+// Usage:
+//
+//	md := clix.DocsMarkdown[Config](clix.DocsOptions{Title: "mytool"})
+func DocsMarkdown[A any](opts DocsOptions) string {
+	return DocsMarkdownType(reflect.TypeOf((*A)(nil)).Elem(), opts)
+}
+
+// DocsMarkdownType is the non-generic version of DocsMarkdown
+func DocsMarkdownType(t reflect.Type, opts DocsOptions) string {
+	level := opts.HeadingLevel
+	if level < 1 {
+		level = 1
+	}
+
+	var b strings.Builder
+	if opts.Title != "" {
+		writeHeading(&b, level, opts.Title)
+		level++
+	}
+	if opts.Description != "" {
+		b.WriteString(opts.Description + "\n\n")
+	}
+	writeDocsSection(&b, DescribeType(t), level, map[reflect.Type]bool{})
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeDocsSection(b *strings.Builder, sec SectionSpec, level int, seen map[reflect.Type]bool) {
+	if sec.Name != "" {
+		writeHeading(b, level, sec.Name)
+		level++
+		if sec.Usage != "" {
+			b.WriteString(sec.Usage + "\n\n")
+		}
+		if sec.Prefix != "" {
+			fmt.Fprintf(b, "Prefix: `%s`\n\n", sec.Prefix)
+		}
+	}
+
+	if len(sec.Args) > 0 {
+		if sec.Name == "" {
+			fmt.Fprintf(b, "Arguments: `%s`\n\n", sec.ArgsUsage())
+		}
+		b.WriteString("| Argument | Type | Default | Description |\n")
+		b.WriteString("|----------|------|---------|-------------|\n")
+		for _, a := range sec.Args {
+			name := "<" + a.Name + ">"
+			if a.Position < 0 {
+				name += "..."
+			}
+			fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", escapeCell(name), escapeCell(a.Type), codeList(nonEmpty(a.Default)), escapeCell(flagDescription(a.flagSpec())))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(sec.Flags) > 0 {
+		b.WriteString("| Flag | Env | Type | Default | Description |\n")
+		b.WriteString("|------|-----|------|---------|-------------|\n")
+		for _, f := range sec.Flags {
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n",
+				codeList(prefixNames("--", append([]string{f.Name}, f.Aliases...))),
+				codeList(f.Env),
+				escapeCell(f.Type),
+				codeList(nonEmpty(f.Default)),
+				escapeCell(flagDescription(f)),
+			)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, l := range sec.Lists {
+		writeDocsList(b, l, level, seen)
+	}
+	for _, m := range sec.Maps {
+		writeDocsMap(b, m, level, seen)
+	}
+	for _, u := range sec.Unions {
+		writeDocsUnion(b, u, level, seen)
+	}
+	for _, sub := range sec.Sections {
+		writeDocsSection(b, sub, level, seen)
+	}
+}
+
+// writeDocsList documents the flags of one element of a slice of structs, named with an N for the index.
+func writeDocsList(b *strings.Builder, l ListSpec, level int, seen map[reflect.Type]bool) {
+	intro := fmt.Sprintf("List: `--%s<flag>` for element N, or `--%s` holding a JSON or YAML array", l.elemPrefix("N"), l.FlagName())
+	writeDocsElem(b, l.Name, l.Usage, intro, indirect(l.GoType.Elem()), l.elemPrefix("N"), level, seen)
+}
+
+// writeDocsMap documents the flags of one instance of a map of structs, named with a <name> for the instance name.
+func writeDocsMap(b *strings.Builder, m MapSpec, level int, seen map[reflect.Type]bool) {
+	intro := fmt.Sprintf("Map: `--%s<flag>` for each instance", m.ElemPrefix("<name>"))
+	if m.Keys != "" {
+		intro += fmt.Sprintf(", the names are listed by `--%s`", m.Keys)
+	}
+	writeDocsElem(b, m.Name, m.Usage, intro, indirect(m.GoType.Elem()), m.ElemPrefix("<name>"), level, seen)
+}
+
+// writeDocsUnion documents the discriminator of a union and the flags of each of its variants.
+func writeDocsUnion(b *strings.Builder, u UnionSpec, level int, seen map[reflect.Type]bool) {
+	writeHeading(b, level, u.Name)
+	if u.Usage != "" {
+		b.WriteString(u.Usage + "\n\n")
+	}
+	fmt.Fprintf(b, "Union: `--%s` selects one of %s", u.Flag, codeList(u.Names()))
+	if len(u.Env) > 0 {
+		fmt.Fprintf(b, ", env %s", codeList(u.Env))
+	}
+	if u.Default != "" {
+		fmt.Fprintf(b, ", default `%s`", u.Default)
+	}
+	if u.Required {
+		b.WriteString(", **required**")
+	}
+	b.WriteString("\n\n")
+	for _, v := range u.Variants {
+		intro := fmt.Sprintf("Read with `--%s %s`", u.Flag, v.Name)
+		writeDocsElem(b, v.Name, "", intro, indirect(v.GoType), v.Prefix, level+1, seen)
+	}
+}
+
+// writeDocsElem documents the struct of the elements of a list or map, or of a union variant, with its flags under prefix.
+// An element of the type of an enclosing element refers to it instead, so recursive types terminate.
+func writeDocsElem(b *strings.Builder, name, usage, intro string, elem reflect.Type, prefix string, level int, seen map[reflect.Type]bool) {
+	writeHeading(b, level, name)
+	if usage != "" {
+		b.WriteString(usage + "\n\n")
+	}
+	b.WriteString(intro + "\n\n")
+	if seen[elem] {
+		fmt.Fprintf(b, "Elements are %s, described above.\n\n", elem.Name())
+		return
+	}
+	seen[elem] = true
+	defer delete(seen, elem)
+
+	writeDocsSection(b, prefixSpec(DescribeType(elem), prefix), level+1, seen)
+}
+
+// flagDescription combines the usage text with the constraints declared on the field.
+func flagDescription(f FlagSpec) string {
+	parts := []string{}
+	if f.Usage != "" {
+		parts = append(parts, strings.TrimRight(f.Usage, "."))
+	}
+	if len(f.Enum) > 0 {
+		parts = append(parts, "One of: "+codeList(f.Enum))
+	}
+	if len(f.Layouts) > 0 {
+		parts = append(parts, "Format: "+codeList(f.Layouts))
+	}
+	if f.TZ != "" {
+		parts = append(parts, "Time zone: "+f.TZ)
+	}
+	if len(f.Path) > 0 {
+		parts = append(parts, "Path: "+codeList(f.Path))
+	}
+	if len(f.Schemes) > 0 {
+		parts = append(parts, "Schemes: "+codeList(f.Schemes))
+	}
+	if f.Port != "" {
+		parts = append(parts, "Default port: "+f.Port)
+	}
+	if f.Min != "" {
+		parts = append(parts, "Min: "+f.Min)
+	}
+	if f.Max != "" {
+		parts = append(parts, "Max: "+f.Max)
+	}
+	if f.Required {
+		parts = append(parts, "**Required**")
+	}
+	if len(f.Deprecated) > 0 {
+		parts = append(parts, "Deprecated names: "+codeList(prefixNames("--", f.Deprecated)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, ". ") + "."
+}
+
+func writeHeading(b *strings.Builder, level int, text string) {
+	if level > 6 {
+		level = 6
+	}
+	b.WriteString(strings.Repeat("#", level) + " " + text + "\n\n")
+}
+
+func codeList(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = "`" + escapeCell(s) + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
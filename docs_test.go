@@ -0,0 +1,100 @@
+package clix
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type DocsConfig struct {
+	Host     string        `cli:"host" cli-env:"HOST,APP_HOST" cli-default:"localhost" cli-usage:"Address to bind to."`
+	Port     int           `cli:"port" cli-default:"8080" cli-min:"1" cli-max:"65535"`
+	Mode     string        `cli:"mode" cli-oneof:"dev,prod" cli-required:"true"`
+	Timeout  time.Duration `cli:"timeout"`
+	Ignored  string
+	Database struct {
+		Name     string   `cli:"name" cli-usage:"database name"`
+		Replicas []string `cli:"replicas"`
+		Pool     struct {
+			Size int `cli:"size"`
+		} `cli-prefix:"pool-"`
+	} `cli-prefix:"db-" cli-usage:"Connection settings."`
+	Empty struct {
+		Nothing string
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	spec := Describe[DocsConfig]()
+
+	assert.Len(t, spec.Flags, 4)
+	assert.Equal(t, FlagSpec{
+		Name:    "host",
+		Key:     "host",
+		Field:   "Host",
+		Index:   []int{0},
+		Type:    "string",
+		GoType:  spec.Flags[0].GoType,
+		Env:     []string{"HOST", "APP_HOST"},
+		Default: "localhost",
+		Usage:   "Address to bind to.",
+	}, spec.Flags[0])
+	assert.Equal(t, []string{"dev", "prod"}, spec.Flags[2].Enum)
+	assert.True(t, spec.Flags[2].Required)
+	assert.Equal(t, "duration", spec.Flags[3].Type)
+
+	// Empty sections are dropped
+	assert.Len(t, spec.Sections, 1)
+	db := spec.Sections[0]
+	assert.Equal(t, "Database", db.Name)
+	assert.Equal(t, "db-", db.Prefix)
+	assert.Equal(t, "db-replicas", db.Flags[1].Name)
+	assert.Equal(t, "replicas", db.Flags[1].Key)
+	assert.Equal(t, "[]string", db.Flags[1].Type)
+	assert.Equal(t, "db-pool-size", db.Sections[0].Flags[0].Name)
+	assert.Equal(t, "Database.Pool.Size", db.Sections[0].Flags[0].Field)
+	assert.Equal(t, []int{5, 2, 0}, db.Sections[0].Flags[0].Index)
+}
+
+func TestDocsMarkdown(t *testing.T) {
+	md := DocsMarkdown[DocsConfig](DocsOptions{Title: "mytool", Description: "Runs things."})
+
+	expected := "# mytool\n" +
+		"\n" +
+		"Runs things.\n" +
+		"\n" +
+		"| Flag | Env | Type | Default | Description |\n" +
+		"|------|-----|------|---------|-------------|\n" +
+		"| `--host` | `HOST`, `APP_HOST` | string | `localhost` | Address to bind to. |\n" +
+		"| `--port` |  | int | `8080` | Min: 1. Max: 65535. |\n" +
+		"| `--mode` |  | string |  | One of: `dev`, `prod`. **Required**. |\n" +
+		"| `--timeout` |  | duration |  |  |\n" +
+		"\n" +
+		"## Database\n" +
+		"\n" +
+		"Connection settings.\n" +
+		"\n" +
+		"Prefix: `db-`\n" +
+		"\n" +
+		"| Flag | Env | Type | Default | Description |\n" +
+		"|------|-----|------|---------|-------------|\n" +
+		"| `--db-name` |  | string |  | database name. |\n" +
+		"| `--db-replicas` |  | []string |  |  |\n" +
+		"\n" +
+		"### Pool\n" +
+		"\n" +
+		"Prefix: `db-pool-`\n" +
+		"\n" +
+		"| Flag | Env | Type | Default | Description |\n" +
+		"|------|-----|------|---------|-------------|\n" +
+		"| `--db-pool-size` |  | int |  |  |\n"
+	assert.Equal(t, expected, md)
+}
+
+func TestDocsMarkdownHeadingLevel(t *testing.T) {
+	md := DocsMarkdown[NestedConfig](DocsOptions{HeadingLevel: 3})
+	assert.Contains(t, md, "### Database\n")
+	assert.Contains(t, md, "### Advanced\n")
+	assert.Contains(t, md, "| `--adv-feature1` |  | bool |  |  |\n")
+}
@@ -0,0 +1,138 @@
+package clix
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CommonHTTP struct {
+	Addr    string `cli:"addr"`
+	Timeout int    `cli:"timeout"`
+}
+
+type CommonDB struct {
+	DSN     string `cli:"dsn" cli-required:"true"`
+	Timeout int    `cli:"timeout"`
+}
+
+type CommonTLS struct {
+	Cert string `cli:"cert"`
+}
+
+func (c *CommonTLS) Validate() error {
+	if c.Cert == "invalid" {
+		return assert.AnError
+	}
+	return nil
+}
+
+type Service struct {
+	CommonHTTP
+	*CommonDB  `cli-prefix:"db-"`
+	*CommonTLS `cli-prefix:"tls-"`
+	Timeout    int `cli:"timeout"` // shadows CommonHTTP.Timeout
+}
+
+func TestDescribeEmbedded(t *testing.T) {
+	spec := Describe[Service]()
+	var names []string
+	spec.Walk(func(_ SectionSpec, f FlagSpec) { names = append(names, f.Name) })
+	assert.Equal(t, []string{"timeout", "addr", "db-dsn", "db-timeout", "tls-cert"}, names)
+	assert.True(t, spec.Sections[0].Embedded)
+	assert.Equal(t, "CommonHTTP.Addr", spec.Sections[0].Flags[0].Field)
+
+	_, err := Compile[Service]()
+	assert.NoError(t, err)
+}
+
+func TestParseEmbedded(t *testing.T) {
+	ctx := newMockContext()
+	ctx.stringMap["addr"] = ":8080"
+	ctx.intMap["timeout"] = 5
+	ctx.stringMap["dsn"] = "postgres://db"
+	ctx.stringMap["db-dsn"] = "postgres://"
+
+	cfg, err := TryParse[Service](ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.Addr)
+	assert.Equal(t, 5, cfg.Timeout)
+	assert.Zero(t, cfg.CommonHTTP.Timeout, "shadowed by Service.Timeout")
+	require.NotNil(t, cfg.CommonDB)
+	assert.Equal(t, "postgres://", cfg.DSN)
+	assert.Nil(t, cfg.CommonTLS, "no tls flag given")
+
+	ctx.stringMap["tls-cert"] = "invalid"
+	_, err = TryParse[Service](ctx)
+	assert.EqualError(t, err, "CommonTLS: "+assert.AnError.Error())
+}
+
+func TestParseEmbeddedPointerRequired(t *testing.T) {
+	cfg, err := TryParse[Service](newMockContext())
+	assert.EqualError(t, err, "--db-dsn: required flag is not set")
+	assert.Nil(t, cfg.CommonDB)
+}
+
+func TestEmbeddedKeepsAllocatedPointers(t *testing.T) {
+	cfg := Service{CommonTLS: &CommonTLS{}}
+	AssignValueToCliFields(&cfg, "", newMockContext())
+	assert.NotNil(t, cfg.CommonTLS)
+}
+
+func TestEmbeddedCollisions(t *testing.T) {
+	type Config struct {
+		CommonHTTP
+		CommonDB
+	}
+	_, err := Compile[Config]()
+	assert.EqualError(t, err, "--timeout: flag is promoted from both CommonHTTP and CommonDB")
+
+	_, err = FlagDefs[Config]()
+	assert.EqualError(t, err, "--timeout: flag is promoted from both CommonHTTP and CommonDB")
+
+	// TryParse does not pick one of the fields
+	ctx := newMockContext()
+	ctx.intMap["timeout"] = 5
+	ctx.stringMap["dsn"] = "postgres://db"
+	_, err = TryParse[Config](ctx)
+	assert.EqualError(t, err, "--timeout: flag is promoted from both CommonHTTP and CommonDB")
+
+	// an own field resolves the collision
+	type Resolved struct {
+		CommonHTTP
+		CommonDB
+		Timeout int `cli:"timeout"`
+	}
+	_, err = Compile[Resolved]()
+	assert.NoError(t, err)
+}
+
+func TestDiffEmbeddedPointer(t *testing.T) {
+	a := Service{}
+	b := Service{CommonDB: &CommonDB{DSN: "x"}}
+	assert.Equal(t, []Change{{Flag: "db-dsn", Field: "CommonDB.DSN", Old: "", New: "x"}}, Diff(a, b))
+}
+
+type CountingBlock struct {
+	Name  string `cli:"name"`
+	calls *int
+}
+
+func (c CountingBlock) Validate() error {
+	if c.calls != nil {
+		*c.calls++
+	}
+	return nil
+}
+
+func TestEmbeddedValidatorCalledOnce(t *testing.T) {
+	type Config struct {
+		CountingBlock
+	}
+	calls := 0
+	cfg := Config{CountingBlock{calls: &calls}}
+	require.NoError(t, validateValue(reflect.ValueOf(&cfg).Elem(), newMockContext(), newOptions(nil)))
+	assert.Equal(t, 1, calls)
+}
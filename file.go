@@ -0,0 +1,248 @@
+package clix
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileReader is a ContextReader backed by a YAML or JSON config file.
+// Nested objects are flattened into flag names by joining their keys with "-", so
+//
+//	db:
+//	  host: localhost
+//
+// is read as the flag "db-host", the same as a flat `db-host: localhost` key.
+// Flag names are looked up with "." and "_" treated as "-", which covers the prefixes a Template is nested by.
+//
+// Values are converted to the requested type on read. A value that does not convert reads as the zero value
+// and the problem is reported by Err.
+type FileReader struct {
+	path   string
+	values map[string]any
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// ReadFile reads and decodes the config file at path
+func ReadFile(path string) (*FileReader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewFileReader(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.path = path
+	return r, nil
+}
+
+// NewFileReader decodes a YAML or JSON document, path is left empty
+func NewFileReader(data []byte) (*FileReader, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	r := &FileReader{values: map[string]any{}}
+	flatten(r.values, "", doc)
+	return r, nil
+}
+
+// flatten copies the nested map m into values, keeping maps under their own key as well for StringMap.
+// Objects in lists are flattened under their index, key-0-name, for slices of structs.
+func flatten(values map[string]any, prefix string, m map[string]any) {
+	for k, v := range m {
+		key := normalizeKey(prefix + k)
+		values[key] = v
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(values, key+"-", v)
+		case []any:
+			for i, item := range v {
+				if sub, ok := item.(map[string]any); ok {
+					flatten(values, key+"-"+strconv.Itoa(i)+"-", sub)
+				}
+			}
+		}
+	}
+}
+
+func normalizeKey(name string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(name)
+}
+
+// Path returns the file the reader was read from
+func (r *FileReader) Path() string {
+	return r.path
+}
+
+// Err returns the conversion errors of the values read so far, joined, or nil
+func (r *FileReader) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errors.Join(r.errs...)
+}
+
+func (r *FileReader) fail(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, &FieldError{Flag: name, Err: err})
+}
+
+// IsSet reports whether the file holds a value for the flag
+func (r *FileReader) IsSet(name string) bool {
+	v, ok := r.values[normalizeKey(name)]
+	return ok && v != nil
+}
+
+// FlagNames returns the keys the file holds a value for, sorted
+func (r *FileReader) FlagNames() []string {
+	names := make([]string, 0, len(r.values))
+	for k, v := range r.values {
+		if v != nil {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// text returns the text form of a scalar value
+func (r *FileReader) text(name string) (string, bool) {
+	v, ok := r.values[normalizeKey(name)]
+	if !ok || v == nil {
+		return "", false
+	}
+	switch v := v.(type) {
+	case []any:
+		if len(v) == 0 {
+			return "", false
+		}
+		r.fail(name, fmt.Errorf("expected a single value, got %T", v))
+		return "", false
+	case map[string]any:
+		r.fail(name, fmt.Errorf("expected a single value, got %T", v))
+		return "", false
+	}
+	return scalarText(v), true
+}
+
+// list returns the text form of each element of a list value, a scalar value is split on commas
+func (r *FileReader) list(name string) ([]string, bool) {
+	v, ok := r.values[normalizeKey(name)]
+	if !ok || v == nil {
+		return nil, false
+	}
+	switch v := v.(type) {
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = scalarText(item)
+		}
+		return items, true
+	case map[string]any:
+		r.fail(name, errors.New("expected a list, got an object"))
+		return nil, false
+	}
+	return splitList(scalarText(v)), true
+}
+
+func scalarText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// fileValue converts the value of the flag to T, recording conversion errors
+func fileValue[T any](r *FileReader, name string) T {
+	var zero T
+	s, ok := r.text(name)
+	if !ok {
+		return zero
+	}
+	v, err := parseText(reflect.TypeOf(zero), s)
+	if err != nil {
+		r.fail(name, err)
+		return zero
+	}
+	return v.Interface().(T)
+}
+
+// fileSlice converts each element of the flag value to T, recording conversion errors
+func fileSlice[T any](r *FileReader, name string) []T {
+	items, ok := r.list(name)
+	if !ok {
+		return nil
+	}
+	values := make([]T, len(items))
+	for i, item := range items {
+		v, err := parseText(reflect.TypeOf(values).Elem(), item)
+		if err != nil {
+			r.fail(name, err)
+			return nil
+		}
+		values[i] = v.Interface().(T)
+	}
+	return values
+}
+
+func (r *FileReader) String(name string) string          { return fileValue[string](r, name) }
+func (r *FileReader) Int(name string) int                { return fileValue[int](r, name) }
+func (r *FileReader) Int32(name string) int32            { return fileValue[int32](r, name) }
+func (r *FileReader) Int64(name string) int64            { return fileValue[int64](r, name) }
+func (r *FileReader) Uint(name string) uint              { return fileValue[uint](r, name) }
+func (r *FileReader) Uint32(name string) uint32          { return fileValue[uint32](r, name) }
+func (r *FileReader) Uint64(name string) uint64          { return fileValue[uint64](r, name) }
+func (r *FileReader) Bool(name string) bool              { return fileValue[bool](r, name) }
+func (r *FileReader) Float32(name string) float32        { return fileValue[float32](r, name) }
+func (r *FileReader) Float64(name string) float64        { return fileValue[float64](r, name) }
+func (r *FileReader) Duration(name string) time.Duration { return fileValue[time.Duration](r, name) }
+func (r *FileReader) StringSlice(name string) []string   { return fileSlice[string](r, name) }
+func (r *FileReader) IntSlice(name string) []int         { return fileSlice[int](r, name) }
+func (r *FileReader) Int64Slice(name string) []int64     { return fileSlice[int64](r, name) }
+func (r *FileReader) UintSlice(name string) []uint       { return fileSlice[uint](r, name) }
+func (r *FileReader) Uint64Slice(name string) []uint64   { return fileSlice[uint64](r, name) }
+func (r *FileReader) Float64Slice(name string) []float64 { return fileSlice[float64](r, name) }
+
+func (r *FileReader) Timestamp(name string) *time.Time {
+	if !r.IsSet(name) {
+		return nil
+	}
+	t := fileValue[time.Time](r, name)
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// StringMap reads an object value, or a comma separated list of key=value pairs
+func (r *FileReader) StringMap(name string) map[string]string {
+	v, ok := r.values[normalizeKey(name)]
+	if !ok || v == nil {
+		return nil
+	}
+	if m, ok := v.(map[string]any); ok {
+		out := make(map[string]string, len(m))
+		for k, item := range m {
+			out[k] = scalarText(item)
+		}
+		return out
+	}
+	return fileValue[map[string]string](r, name)
+}
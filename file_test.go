@@ -0,0 +1,107 @@
+package clix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type FileConfig struct {
+	Host    string            `cli:"host"`
+	Port    int               `cli:"port"`
+	Workers int32             `cli:"workers"`
+	Ratio   float32           `cli:"ratio"`
+	Debug   bool              `cli:"debug"`
+	Timeout time.Duration     `cli:"timeout"`
+	Start   time.Time         `cli:"start"`
+	Tags    []string          `cli:"tags"`
+	IDs     []int             `cli:"ids"`
+	Labels  map[string]string `cli:"labels"`
+	DB      struct {
+		Name string `cli:"name"`
+		Pool struct {
+			Size int `cli:"size"`
+		} `cli-prefix:"pool."`
+	} `cli-prefix:"db-"`
+}
+
+func TestFileReaderYAML(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+host: example.com
+port: 8080
+workers: 4
+ratio: 0.5
+debug: true
+timeout: 1m30s
+start: 2024-01-02T03:04:05Z
+tags: [a, b]
+ids: 1,2,3
+labels:
+  env: prod
+db:
+  name: app
+  pool:
+    size: 10
+`))
+	require.NoError(t, err)
+
+	cfg := Parse[FileConfig](r)
+	require.NoError(t, r.Err())
+	assert.Equal(t, "example.com", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, int32(4), cfg.Workers)
+	assert.Equal(t, float32(0.5), cfg.Ratio)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 90*time.Second, cfg.Timeout)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Start)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, []int{1, 2, 3}, cfg.IDs)
+	assert.Equal(t, map[string]string{"env": "prod"}, cfg.Labels)
+	assert.Equal(t, "app", cfg.DB.Name)
+	assert.Equal(t, 10, cfg.DB.Pool.Size)
+
+	assert.True(t, r.IsSet("db-pool.size"))
+	assert.False(t, r.IsSet("missing"))
+}
+
+func TestFileReaderJSONFlatKeys(t *testing.T) {
+	r, err := NewFileReader([]byte(`{"host": "localhost", "db-name": "app", "labels": "a=1,b=2"}`))
+	require.NoError(t, err)
+
+	cfg := Parse[FileConfig](r)
+	require.NoError(t, r.Err())
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, "app", cfg.DB.Name)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, cfg.Labels)
+	assert.Nil(t, cfg.Tags)
+}
+
+func TestFileReaderConversionErrors(t *testing.T) {
+	r, err := NewFileReader([]byte("port: eighty\ntags: {a: 1}\n"))
+	require.NoError(t, err)
+
+	cfg := Parse[FileConfig](r)
+	assert.Zero(t, cfg.Port)
+	assert.ErrorContains(t, r.Err(), "--port: ")
+	assert.ErrorContains(t, r.Err(), "--tags: expected a list, got an object")
+
+	_, err = NewFileReader([]byte("host: [unterminated"))
+	assert.Error(t, err)
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("host: example.com\n"), 0o644))
+
+	r, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, r.Path())
+	assert.Equal(t, "example.com", r.String("host"))
+
+	_, err = ReadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
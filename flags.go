@@ -0,0 +1,223 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FlagDef is a flag that Parse reads, resolved to what a cli framework needs to declare it.
+// The adapters, such as clixv3.Flags, turn each FlagDef into a flag of their framework.
+type FlagDef struct {
+	Name    string
+	Aliases []string
+	Usage   string
+	Env     []string
+	Hidden  bool // deprecated names are hidden flags, so that Parse can tell which name was used
+	// Type is the type of the value of the flag: string, bool, one of the sized ints, uints and floats,
+	// time.Duration, time.Time, map[string]string, or a slice of string, int, int64, uint, uint64 or float64.
+	// Types read from their text, such as clix.ByteSize, are string flags, or string slice flags for slices.
+	Type  reflect.Type
+	Value any // the default, of Type, nil when there is none
+
+	Layouts  []string       // layouts a time.Time flag is read with
+	Location *time.Location // zone of a time.Time flag given without one, nil for UTC
+}
+
+// FlagDefs returns the flags that Parse reads into A, for the adapters of cli frameworks.
+// Names come from the `cli` and `cli-prefix` tags, and usage, env vars and default values
+// from `cli-usage`, `cli-env` and `cli-default`. A flag name used by several fields is only returned once.
+// Each deprecated name gets its own hidden flag. Lists are given whole as a JSON or YAML array, and the
+// instances of a map of structs get flags only when they are named by its `cli-default`, other instances
+// are read from config files or readers that list their flags, see MapSpec.
+func FlagDefs[A any](opts ...Option) ([]FlagDef, error) {
+	return FlagDefsType(reflect.TypeOf((*A)(nil)).Elem(), opts...)
+}
+
+// FlagDefsType is FlagDefs for a reflect.Type
+func FlagDefsType(t reflect.Type, opts ...Option) ([]FlagDef, error) {
+	o := newOptions(opts)
+	var defs []FlagDef
+	errs := &ParseError{}
+	appendFlagDefs(describeType(t, o.naming), o, &defs, map[string]bool{}, map[reflect.Type]bool{}, errs)
+	return defs, errs.orNil()
+}
+
+// appendFlagDefs appends the flags of spec that are not seen yet, the flags of union variants included.
+// A variant holding a union that is already being expanded is skipped, so recursive types terminate.
+func appendFlagDefs(spec SectionSpec, o *options, defs *[]FlagDef, seen map[string]bool, variants map[reflect.Type]bool, errs *ParseError) {
+	collisions(spec, errs)
+	spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		if seen[f.Name] {
+			return
+		}
+		seen[f.Name] = true
+
+		def, err := flagDef(f, false, o)
+		if err != nil {
+			errs.add(f.Name, f.Field, err)
+			return
+		}
+		*defs = append(*defs, def)
+
+		for _, name := range f.Deprecated {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			dep := FlagSpec{Name: name, GoType: f.GoType, Layouts: f.Layouts, TZ: f.TZ, Usage: "deprecated, use --" + f.Name}
+			def, err := flagDef(dep, true, o)
+			if err != nil {
+				errs.add(name, f.Field, err)
+				continue
+			}
+			*defs = append(*defs, def)
+		}
+	})
+
+	// the elements of a list have no flags of their own, the list is given whole as a JSON or YAML array
+	spec.WalkLists(func(_ SectionSpec, l ListSpec) {
+		name := l.FlagName()
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		*defs = append(*defs, FlagDef{Name: name, Usage: strings.TrimSpace(l.Usage + " (JSON or YAML array)"), Type: stringType})
+	})
+
+	// the instances of a map are named by its keys flag, when it has one. Only the instances named by
+	// its cli-default get flags, the others can not be given on the command line.
+	spec.WalkMaps(func(_ SectionSpec, m MapSpec) {
+		if m.Keys != "" && !seen[m.Keys] {
+			seen[m.Keys] = true
+			usage := m.Usage
+			if usage == "" {
+				usage = "names of the " + m.Name
+			}
+			def := FlagDef{Name: m.Keys, Usage: usage, Type: stringsType}
+			if m.Default != nil {
+				def.Value = m.Default
+			}
+			*defs = append(*defs, def)
+		}
+		for _, key := range m.Default {
+			appendFlagDefs(m.ElemSpec(key), o, defs, seen, variants, errs)
+		}
+	})
+
+	// a union is given as its discriminator and the flags of every variant, Parse reports those of the others
+	spec.WalkUnions(func(_ SectionSpec, u UnionSpec) {
+		if !seen[u.Flag] {
+			seen[u.Flag] = true
+			disc := FlagSpec{
+				Name:    u.Flag,
+				GoType:  stringType,
+				Env:     u.Env,
+				Default: u.Default,
+				Usage:   strings.TrimSpace(u.Usage + " (" + strings.Join(u.Names(), ", ") + ")"),
+			}
+			if def, err := flagDef(disc, false, o); err != nil {
+				errs.add(u.Flag, u.Field, err)
+			} else {
+				*defs = append(*defs, def)
+			}
+		}
+		for _, v := range u.Variants {
+			if variants[v.GoType] {
+				continue
+			}
+			variants[v.GoType] = true
+			appendFlagDefs(v.Spec(), o, defs, seen, variants, errs)
+			delete(variants, v.GoType)
+		}
+	})
+}
+
+var (
+	stringType  = reflect.TypeOf("")
+	stringsType = reflect.TypeOf([]string{})
+)
+
+// flagDef resolves the flag of f, hidden flags are not listed in help
+func flagDef(f FlagSpec, hidden bool, o *options) (FlagDef, error) {
+	def := FlagDef{Name: f.Name, Aliases: f.Aliases, Usage: f.Usage, Env: f.Env, Hidden: hidden}
+	var (
+		text bool
+		err  error
+	)
+	if def.Type, text, err = flagType(f, o); err != nil {
+		return FlagDef{}, err
+	}
+	if def.Type == timeType {
+		def.Layouts = layoutsOf(f)
+		if def.Location, err = locationOf(f); err != nil {
+			return FlagDef{}, err
+		}
+	}
+	if f.Default == "" {
+		return def, nil
+	}
+
+	// types read from their text keep the default as written, once it is checked against the type
+	if text {
+		if _, err := parseTagValue(f, f.Default); err != nil {
+			return FlagDef{}, fmt.Errorf("invalid default %q: %w", f.Default, err)
+		}
+		f.GoType = def.Type
+	}
+	value, err := parseTagValue(f, f.Default)
+	if err != nil {
+		return FlagDef{}, fmt.Errorf("invalid default %q: %w", f.Default, err)
+	}
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if !value.Type().ConvertibleTo(def.Type) {
+		return FlagDef{}, fmt.Errorf("default %q can not be used as %s", f.Default, def.Type)
+	}
+	def.Value = value.Convert(def.Type).Interface()
+	return def, nil
+}
+
+// flagType returns the type of the value of the flag of f, see FlagDef.Type, and whether f is read from its text
+func flagType(f FlagSpec, o *options) (t reflect.Type, text bool, err error) {
+	// with WithExtendedTime, durations and times are read from their text, "7d" or "now-1h", see readsText
+	extended := o.extendedTime && (f.GoType == durationType || isTime(f.GoType))
+	switch {
+	case extended, isTextType(f.GoType):
+		return stringType, true, nil
+	case f.GoType.Kind() == reflect.Slice && isTextType(f.GoType.Elem()):
+		return stringsType, true, nil
+	case f.GoType == durationType:
+		return durationType, false, nil
+	case isTime(f.GoType):
+		return timeType, false, nil
+	}
+
+	switch k := f.GoType.Kind(); k {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return kindType(k), false, nil
+	case reflect.Map:
+		if f.GoType.Key().Kind() == reflect.String && f.GoType.Elem().Kind() == reflect.String {
+			return reflect.TypeOf(map[string]string{}), false, nil
+		}
+	case reflect.Slice:
+		switch k := f.GoType.Elem().Kind(); k {
+		case reflect.String, reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
+			return reflect.SliceOf(kindType(k)), false, nil
+		}
+	}
+	return nil, false, fmt.Errorf("no flag for type %s", f.GoType)
+}
+
+// kindType returns the unnamed type of the basic kind k
+func kindType(k reflect.Kind) reflect.Type {
+	return map[reflect.Kind]reflect.Type{
+		reflect.String: stringType, reflect.Bool: reflect.TypeOf(false),
+		reflect.Int: reflect.TypeOf(0), reflect.Int32: reflect.TypeOf(int32(0)), reflect.Int64: reflect.TypeOf(int64(0)),
+		reflect.Uint: reflect.TypeOf(uint(0)), reflect.Uint32: reflect.TypeOf(uint32(0)), reflect.Uint64: reflect.TypeOf(uint64(0)),
+		reflect.Float32: reflect.TypeOf(float32(0)), reflect.Float64: reflect.TypeOf(0.0),
+	}[k]
+}
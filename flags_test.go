@@ -0,0 +1,64 @@
+package clix
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type FlagsConfig struct {
+	Name     string            `cli:"name" cli-usage:"name of the app" cli-env:"FLAGS_TEST_NAME" cli-default:"app"`
+	Port     int               `cli:"port" cli-default:"8080"`
+	Big      int64             `cli:"big"`
+	Small    int32             `cli:"small"`
+	Count    uint              `cli:"count"`
+	Ratio    float64           `cli:"ratio" cli-default:"0.5"`
+	Debug    bool              `cli:"debug"`
+	Timeout  time.Duration     `cli:"timeout" cli-default:"5s"`
+	Start    time.Time         `cli:"start"`
+	Tags     []string          `cli:"tags" cli-default:"a,b"`
+	Weights  []float64         `cli:"weights"`
+	Labels   map[string]string `cli:"labels"`
+	Database struct {
+		Host string `cli:"host" cli-default:"localhost"`
+		Name string `cli:"name"`
+	} `cli-prefix:"db-"`
+	Again struct {
+		Name string `cli:"name"`
+	}
+}
+
+func TestFlagDefs(t *testing.T) {
+	defs, err := FlagDefs[FlagsConfig]()
+	require.NoError(t, err)
+
+	// "name" is used twice but only declared once
+	names := []string{}
+	for _, d := range defs {
+		names = append(names, d.Name)
+	}
+	assert.Equal(t, []string{"name", "port", "big", "small", "count", "ratio", "debug", "timeout", "start", "tags", "weights", "labels", "db-host", "db-name"}, names)
+
+	assert.Equal(t, FlagDef{Name: "name", Usage: "name of the app", Env: []string{"FLAGS_TEST_NAME"}, Type: stringType, Value: "app"}, defs[0])
+	assert.Equal(t, 8080, defs[1].Value)
+	assert.Equal(t, reflect.TypeOf(int64(0)), defs[2].Type)
+	assert.Equal(t, reflect.TypeOf(int32(0)), defs[3].Type)
+	assert.Equal(t, 5*time.Second, defs[7].Value)
+	assert.Equal(t, timeType, defs[8].Type)
+	assert.Equal(t, []string{time.RFC3339}, defs[8].Layouts)
+	assert.Equal(t, []string{"a", "b"}, defs[9].Value)
+	assert.Equal(t, reflect.TypeOf(map[string]string{}), defs[11].Type)
+}
+
+func TestFlagDefsErrors(t *testing.T) {
+	type Config struct {
+		Port int            `cli:"port" cli-default:"eighty"`
+		Ch   chan int       `cli:"ch"`
+		Set  map[string]int `cli:"set"`
+	}
+	_, err := FlagDefs[Config]()
+	assert.EqualError(t, err, `--port: invalid default "eighty": strconv.ParseInt: parsing "eighty": invalid syntax; --ch: no flag for type chan int; --set: no flag for type map[string]int`)
+}
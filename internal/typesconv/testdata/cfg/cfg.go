@@ -0,0 +1,22 @@
+package cfg
+
+import "time"
+
+type Level int
+
+type Common struct {
+	Verbose bool `cli:"verbose"`
+}
+
+type Config struct {
+	Common
+	Host    string        `cli:"host" cli-usage:"address to bind"`
+	Level   Level         `cli:"level"`
+	Timeout time.Duration `cli:"timeout" cli-default:"5s"`
+	Tags    []string      `cli:"tags"`
+	Hook    func()        `cli:"hook"`
+	secret  string
+	DB      struct {
+		Name string `cli:"name"`
+	} `cli-prefix:"db-"`
+}
@@ -0,0 +1,202 @@
+// Package typesconv loads Go types with go/packages and mirrors them as reflect types,
+// so that tools can run the clix struct walkers on code they never compile or execute.
+package typesconv
+
+import (
+	"fmt"
+	"go/types"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/modfin/clix"
+	"golang.org/x/tools/go/packages"
+)
+
+// Known maps qualified type names ("time.Duration") to the reflect type they should be mirrored as.
+// Named types that are not listed here are mirrored by their underlying type.
+var Known = map[string]reflect.Type{
+	"time.Duration": reflect.TypeOf(time.Duration(0)),
+	"time.Time":     reflect.TypeOf(time.Time{}),
+
+	"net/url.URL":        reflect.TypeOf(url.URL{}),
+	"net/netip.Addr":     reflect.TypeOf(netip.Addr{}),
+	"net/netip.AddrPort": reflect.TypeOf(netip.AddrPort{}),
+	"net/netip.Prefix":   reflect.TypeOf(netip.Prefix{}),
+
+	"regexp.Regexp":          reflect.TypeOf(regexp.Regexp{}),
+	"text/template.Template": reflect.TypeOf(template.Template{}),
+
+	"github.com/modfin/clix.ByteSize": reflect.TypeOf(clix.ByteSize(0)),
+	"github.com/modfin/clix.Percent":  reflect.TypeOf(clix.Percent(0)),
+	"github.com/modfin/clix.Rate":     reflect.TypeOf(clix.Rate{}),
+	"github.com/modfin/clix.HostPort": reflect.TypeOf(clix.HostPort{}),
+}
+
+// Load loads the package matching pattern and looks up the named type in it.
+func Load(dir, pattern, name string) (*types.Named, *packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading %s: %w", pattern, err)
+	}
+	if len(pkgs) != 1 {
+		return nil, nil, fmt.Errorf("pattern %s matched %d packages, expected 1", pattern, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, nil, fmt.Errorf("loading %s: %v", pattern, pkg.Errors[0])
+	}
+
+	obj := pkg.Types.Scope().Lookup(name)
+	if obj == nil {
+		return nil, nil, fmt.Errorf("type %s not found in %s", name, pkg.PkgPath)
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not a named type", name)
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil, nil, fmt.Errorf("%s is not a struct type", name)
+	}
+	return named, pkg, nil
+}
+
+// Reflect mirrors t as a reflect type.
+// Struct types are rebuilt with reflect.StructOf keeping field names and tags, unexported fields
+// and fields of types that have no reflect counterpart (channels, funcs, interfaces) are dropped.
+func Reflect(t types.Type) (reflect.Type, error) {
+	return (&converter{seen: map[*types.Named]bool{}}).convert(t)
+}
+
+type converter struct {
+	seen map[*types.Named]bool
+}
+
+func (c *converter) convert(t types.Type) (reflect.Type, error) {
+	switch t := t.(type) {
+	case *types.Alias:
+		return c.convert(types.Unalias(t))
+	case *types.Named:
+		if rt, ok := Known[qualifiedName(t)]; ok {
+			return rt, nil
+		}
+		if c.seen[t] {
+			return nil, fmt.Errorf("recursive type %s", t)
+		}
+		c.seen[t] = true
+		defer delete(c.seen, t)
+		return c.convert(t.Underlying())
+	case *types.Basic:
+		if rt, ok := basics[t.Kind()]; ok {
+			return rt, nil
+		}
+	case *types.Pointer:
+		elem, err := c.convert(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PointerTo(elem), nil
+	case *types.Slice:
+		elem, err := c.convert(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	case *types.Map:
+		key, err := c.convert(t.Key())
+		if err != nil {
+			return nil, err
+		}
+		elem, err := c.convert(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(key, elem), nil
+	case *types.Struct:
+		return c.convertStruct(t)
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+func (c *converter) convertStruct(t *types.Struct) (reflect.Type, error) {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumFields(); i++ {
+		f := t.Field(i)
+		if !f.Exported() {
+			continue
+		}
+		ft, err := c.convert(f.Type())
+		if err != nil {
+			// Parse skips fields it does not understand, so do the tools
+			continue
+		}
+		fields = append(fields, reflect.StructField{
+			Name:      f.Name(),
+			Type:      ft,
+			Tag:       reflect.StructTag(t.Tag(i)),
+			Anonymous: f.Embedded(),
+		})
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// FieldType returns the go/types type of the field at the dot separated path, e.g. "Database.Host".
+func FieldType(t types.Type, path string) (types.Type, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for {
+			if p, ok := t.Underlying().(*types.Pointer); ok {
+				t = p.Elem()
+				continue
+			}
+			break
+		}
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return nil, false
+		}
+		found := false
+		for i := 0; i < st.NumFields(); i++ {
+			if st.Field(i).Name() == name {
+				t, found = st.Field(i).Type(), true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return t, true
+}
+
+func qualifiedName(t *types.Named) string {
+	obj := t.Obj()
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+var basics = map[types.BasicKind]reflect.Type{
+	types.Bool:    reflect.TypeOf(false),
+	types.String:  reflect.TypeOf(""),
+	types.Int:     reflect.TypeOf(int(0)),
+	types.Int8:    reflect.TypeOf(int8(0)),
+	types.Int16:   reflect.TypeOf(int16(0)),
+	types.Int32:   reflect.TypeOf(int32(0)),
+	types.Int64:   reflect.TypeOf(int64(0)),
+	types.Uint:    reflect.TypeOf(uint(0)),
+	types.Uint8:   reflect.TypeOf(uint8(0)),
+	types.Uint16:  reflect.TypeOf(uint16(0)),
+	types.Uint32:  reflect.TypeOf(uint32(0)),
+	types.Uint64:  reflect.TypeOf(uint64(0)),
+	types.Float32: reflect.TypeOf(float32(0)),
+	types.Float64: reflect.TypeOf(float64(0)),
+}
@@ -0,0 +1,62 @@
+package typesconv
+
+import (
+	"go/types"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAndReflect(t *testing.T) {
+	named, pkg, err := Load("", "./testdata/cfg", "Config")
+	require.NoError(t, err)
+	assert.Equal(t, "cfg", pkg.Name)
+
+	rt, err := Reflect(named)
+	require.NoError(t, err)
+
+	// Unexported fields and funcs are dropped
+	assert.Equal(t, 6, rt.NumField())
+	assert.True(t, rt.Field(0).Anonymous)
+
+	host, _ := rt.FieldByName("Host")
+	assert.Equal(t, reflect.TypeOf(""), host.Type)
+	assert.Equal(t, "address to bind", host.Tag.Get("cli-usage"))
+
+	level, _ := rt.FieldByName("Level")
+	assert.Equal(t, reflect.Int, level.Type.Kind())
+
+	timeout, _ := rt.FieldByName("Timeout")
+	assert.Equal(t, reflect.TypeOf(time.Duration(0)), timeout.Type)
+
+	db, _ := rt.FieldByName("DB")
+	assert.Equal(t, "db-", db.Tag.Get("cli-prefix"))
+	assert.Equal(t, reflect.Struct, db.Type.Kind())
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, _, err := Load("", "./testdata/cfg", "Missing")
+	assert.Error(t, err)
+
+	_, _, err = Load("", "./testdata/cfg", "Level")
+	assert.Error(t, err)
+}
+
+func TestFieldType(t *testing.T) {
+	named, _, err := Load("", "./testdata/cfg", "Config")
+	require.NoError(t, err)
+
+	ft, ok := FieldType(named, "DB.Name")
+	require.True(t, ok)
+	assert.Equal(t, types.Typ[types.String], ft)
+
+	ft, ok = FieldType(named, "Level")
+	require.True(t, ok)
+	assert.Equal(t, "github.com/modfin/clix/internal/typesconv/testdata/cfg.Level", ft.String())
+
+	_, ok = FieldType(named, "DB.Missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,181 @@
+package clix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Upstream struct {
+	Host   string `cli:"host" cli-required:"true"`
+	Port   int    `cli:"port" cli-default:"80" cli-max:"65535"`
+	Weight int    `cli:"weight"`
+}
+
+func (u *Upstream) Validate() error {
+	if u.Host == "localhost" && u.Port == 22 {
+		return errors.New("not an http upstream")
+	}
+	return nil
+}
+
+type Proxy struct {
+	Listen    string      `cli:"listen"`
+	Upstreams []Upstream  `cli-prefix:"upstream-" cli-usage:"backends to proxy to"`
+	Mirrors   []*Upstream `cli-prefix:"mirror."`
+}
+
+func TestDescribeList(t *testing.T) {
+	spec := Describe[Proxy]()
+	require.Len(t, spec.Lists, 2)
+	l := spec.Lists[0]
+	assert.Equal(t, "Upstreams", l.Field)
+	assert.Equal(t, "upstream-", l.Prefix)
+	assert.Equal(t, "upstream", l.FlagName())
+	assert.Equal(t, "upstream-3-", l.ElemPrefix(3))
+	assert.Equal(t, "mirror.0.", spec.Lists[1].ElemPrefix(0))
+
+	// the element flags are not flags of the parent
+	var names []string
+	spec.Walk(func(_ SectionSpec, f FlagSpec) { names = append(names, f.Name) })
+	assert.Equal(t, []string{"listen"}, names)
+
+	_, err := Compile[Proxy]()
+	assert.NoError(t, err)
+}
+
+func TestParseListIndexed(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+listen: ":80"
+upstream:
+  - host: a.example.com
+    port: 8080
+  - host: b.example.com
+mirror.5.host: c.example.com
+`))
+	require.NoError(t, err)
+
+	cfg, err := TryParse[Proxy](r)
+	require.NoError(t, err)
+	assert.Equal(t, ":80", cfg.Listen)
+	assert.Equal(t, []Upstream{
+		{Host: "a.example.com", Port: 8080},
+		{Host: "b.example.com", Port: 80},
+	}, cfg.Upstreams)
+	require.Len(t, cfg.Mirrors, 1)
+	assert.Equal(t, "c.example.com", cfg.Mirrors[0].Host)
+}
+
+func TestParseListFromValue(t *testing.T) {
+	ctx := newMockContext()
+	ctx.stringMap["upstream"] = `[{"host": "a", "port": 81}, {"host": "b", "weight": 2}]`
+
+	cfg, err := TryParse[Proxy](ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Upstream{
+		{Host: "a", Port: 81},
+		{Host: "b", Port: 80, Weight: 2},
+	}, cfg.Upstreams)
+	assert.Nil(t, cfg.Mirrors)
+
+	// YAML flow style works as well
+	ctx.stringMap["upstream"] = `[{host: a}]`
+	cfg, err = TryParse[Proxy](ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Upstream{{Host: "a", Port: 80}}, cfg.Upstreams)
+
+	ctx.stringMap["upstream"] = `{"host": "a"}`
+	_, err = TryParse[Proxy](ctx)
+	assert.ErrorContains(t, err, "--upstream: invalid list")
+
+	ctx.stringMap["upstream"] = `[{"host": "a", "port": "eighty"}]`
+	_, err = TryParse[Proxy](ctx)
+	assert.ErrorContains(t, err, "--upstream: --port: ")
+}
+
+func TestValidateList(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+upstream:
+  - host: a
+  - port: 8080
+  - host: localhost
+    port: 22
+  - host: c
+    port: 70000
+`))
+	require.NoError(t, err)
+
+	_, err = TryParse[Proxy](r)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"--upstream-1-host: required flag is not set",
+		"Upstreams[2]: not an http upstream",
+		"--upstream-3-port: 70000 is greater than 65535",
+	}, messages)
+	assert.Equal(t, "Upstreams[1].Host", pe.Errors[0].Field)
+}
+
+func TestCompileListErrors(t *testing.T) {
+	type Backend struct {
+		Port int `cli:"port" cli-default:"eighty"`
+	}
+	type Config struct {
+		Backends []Backend `cli-prefix:"backend-"`
+	}
+	_, err := Compile[Config]()
+	assert.EqualError(t, err, `--backend-N-port: invalid cli-default "eighty": strconv.ParseInt: parsing "eighty": invalid syntax`)
+}
+
+type Node struct {
+	Name     string `cli:"name"`
+	Children []Node `cli-prefix:"child-"`
+}
+
+func TestParseListRecursive(t *testing.T) {
+	_, err := Compile[Node]()
+	require.NoError(t, err)
+
+	r, err := NewFileReader([]byte(`
+name: root
+child:
+  - name: a
+    child:
+      - name: a1
+  - name: b
+`))
+	require.NoError(t, err)
+	cfg, err := TryParse[Node](r)
+	require.NoError(t, err)
+	assert.Equal(t, Node{Name: "root", Children: []Node{
+		{Name: "a", Children: []Node{{Name: "a1"}}},
+		{Name: "b"},
+	}}, cfg)
+
+	md := DocsMarkdown[Node](DocsOptions{})
+	assert.Contains(t, md, "`--child-N-name`")
+	assert.Contains(t, md, "Elements are Node, described above.")
+}
+
+func TestDiffList(t *testing.T) {
+	a := Proxy{Upstreams: []Upstream{{Host: "a", Port: 80}}}
+	b := Proxy{Upstreams: []Upstream{{Host: "a", Port: 81}, {Host: "b"}}}
+	assert.Equal(t, []Change{
+		{Flag: "upstream-0-port", Field: "Upstreams[0].Port", Old: 80, New: 81},
+		{Flag: "upstream-1-host", Field: "Upstreams[1].Host", Old: "", New: "b"},
+	}, Diff(a, b))
+}
+
+func TestDocsList(t *testing.T) {
+	md := DocsMarkdown[Proxy](DocsOptions{})
+	assert.True(t, strings.Contains(md, "# Upstreams\n\nbackends to proxy to\n\nList: `--upstream-N-<flag>` for element N, or `--upstream` holding a JSON or YAML array"), md)
+	assert.Contains(t, md, "| `--upstream-N-host` |")
+	assert.Contains(t, md, "`--mirror.N.host`")
+}
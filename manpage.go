@@ -0,0 +1,242 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ManPage renders a roff man page for a tool that parses its configuration into A.
+// The OPTIONS section lists every flag, grouped by nested struct, and the ENVIRONMENT section every `cli-env` variable.
+// Usage:
+//
+//	page := clix.ManPage[Config]("mytool", 1)
+//	os.WriteFile("mytool.1", []byte(page), 0644)
+func ManPage[A any](name string, section int) string {
+	m := manPage{
+		name:    name,
+		section: section,
+		spec:    DescribeType(reflect.TypeOf((*A)(nil)).Elem()),
+	}
+	return m.render()
+}
+
+// ManCommand is a command of a command tree, as rendered by ManPageTree.
+// The adapters build it from the commands of their framework, see clixv3.ManPage.
+type ManCommand struct {
+	Name        string
+	Usage       string
+	Description string
+	Flags       []ManFlag    // the visible flags of the command
+	Commands    []ManCommand // the visible subcommands
+}
+
+// ManFlag is a flag of a ManCommand
+type ManFlag struct {
+	Names []string
+	Usage string
+	Env   []string
+}
+
+// ManPageTree renders a roff man page for a command tree whose root flags are parsed into A.
+// Name, usage and description are taken from root, and a COMMANDS section is added for its subcommands.
+// The flags of root are not listed, the OPTIONS section holds those of A.
+func ManPageTree[A any](root ManCommand, section int) string {
+	m := manPage{
+		name:        root.Name,
+		usage:       root.Usage,
+		description: root.Description,
+		section:     section,
+		spec:        DescribeType(reflect.TypeOf((*A)(nil)).Elem()),
+		commands:    root.Commands,
+	}
+	return m.render()
+}
+
+type manPage struct {
+	name        string
+	usage       string
+	description string
+	section     int
+	spec        SectionSpec
+	commands    []ManCommand
+}
+
+func (m manPage) render() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ".TH %s %d\n", strings.ToUpper(roffEscape(m.name)), m.section)
+
+	b.WriteString(".SH NAME\n")
+	if m.usage != "" {
+		fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(m.name), roffEscape(m.usage))
+	} else {
+		b.WriteString(roffEscape(m.name) + "\n")
+	}
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, ".B %s\n", roffEscape(m.name))
+	b.WriteString("[\\fIOPTIONS\\fR]\n")
+	if len(m.commands) > 0 {
+		b.WriteString("\\fICOMMAND\\fR [\\fICOMMAND OPTIONS\\fR]\n")
+	}
+
+	if m.description != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		b.WriteString(roffText(m.description) + "\n")
+	}
+
+	if !m.spec.IsEmpty() {
+		b.WriteString(".SH OPTIONS\n")
+		writeManSection(&b, m.spec)
+	}
+
+	if len(m.commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, cmd := range m.commands {
+			writeManCommand(&b, cmd, nil)
+		}
+	}
+
+	env := m.environment()
+	if len(env) > 0 {
+		b.WriteString(".SH ENVIRONMENT\n")
+		for _, e := range env {
+			fmt.Fprintf(&b, ".TP\n.B %s\n", roffEscape(e.name))
+			fmt.Fprintf(&b, "Sets \\fB%s\\fR.\n", roffFlag(e.flag))
+		}
+	}
+
+	return b.String()
+}
+
+func writeManSection(b *strings.Builder, sec SectionSpec) {
+	if sec.Name != "" {
+		fmt.Fprintf(b, ".SS %s\n", roffEscape(strings.ReplaceAll(sec.Field, ".", " ")))
+		if sec.Usage != "" {
+			b.WriteString(roffText(sec.Usage) + "\n")
+		}
+	}
+	for _, f := range sec.Flags {
+		b.WriteString(".TP\n")
+		names := make([]string, 0, 1+len(f.Aliases))
+		for _, n := range append([]string{f.Name}, f.Aliases...) {
+			names = append(names, "\\fB"+roffFlag(n)+"\\fR")
+		}
+		if f.Type == "bool" {
+			fmt.Fprintf(b, "%s\n", strings.Join(names, ", "))
+		} else {
+			fmt.Fprintf(b, "%s=\\fI%s\\fR\n", strings.Join(names, ", "), roffEscape(f.Type))
+		}
+		if desc := manFlagDescription(f); desc != "" {
+			b.WriteString(roffText(desc) + "\n")
+		}
+	}
+	for _, sub := range sec.Sections {
+		writeManSection(b, sub)
+	}
+}
+
+func writeManCommand(b *strings.Builder, cmd ManCommand, parents []string) {
+	path := append(append([]string{}, parents...), cmd.Name)
+	fmt.Fprintf(b, ".SS %s\n", roffEscape(strings.Join(path, " ")))
+	if cmd.Usage != "" {
+		b.WriteString(roffText(cmd.Usage) + "\n")
+	}
+	for _, fl := range cmd.Flags {
+		names := make([]string, len(fl.Names))
+		for i, n := range fl.Names {
+			names[i] = roffFlag(n)
+		}
+		b.WriteString(".TP\n")
+		fmt.Fprintf(b, "\\fB%s\\fR\n", strings.Join(names, "\\fR, \\fB"))
+		if fl.Usage != "" {
+			b.WriteString(roffText(fl.Usage) + "\n")
+		}
+	}
+	for _, sub := range cmd.Commands {
+		writeManCommand(b, sub, path)
+	}
+}
+
+type manEnv struct {
+	name string
+	flag string
+}
+
+// environment lists the env vars of the struct flags followed by those of the command flags
+func (m manPage) environment() []manEnv {
+	var env []manEnv
+	m.spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		for _, e := range f.Env {
+			env = append(env, manEnv{name: e, flag: f.Name})
+		}
+	})
+
+	var walk func(cmds []ManCommand)
+	walk = func(cmds []ManCommand) {
+		for _, cmd := range cmds {
+			for _, fl := range cmd.Flags {
+				for _, e := range fl.Env {
+					env = append(env, manEnv{name: e, flag: fl.Names[0]})
+				}
+			}
+			walk(cmd.Commands)
+		}
+	}
+	walk(m.commands)
+	return env
+}
+
+func manFlagDescription(f FlagSpec) string {
+	parts := []string{}
+	if f.Usage != "" {
+		parts = append(parts, strings.TrimRight(f.Usage, "."))
+	}
+	if len(f.Enum) > 0 {
+		parts = append(parts, "One of: "+strings.Join(f.Enum, ", "))
+	}
+	if f.Min != "" {
+		parts = append(parts, "Min: "+f.Min)
+	}
+	if f.Max != "" {
+		parts = append(parts, "Max: "+f.Max)
+	}
+	if f.Default != "" {
+		parts = append(parts, "Default: "+f.Default)
+	}
+	if f.Required {
+		parts = append(parts, "Required")
+	}
+	if len(f.Deprecated) > 0 {
+		parts = append(parts, "Deprecated names: --"+strings.Join(f.Deprecated, ", --"))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, ". ") + "."
+}
+
+func roffFlag(name string) string {
+	if len(name) == 1 {
+		return "\\-" + roffEscape(name)
+	}
+	return "\\-\\-" + roffEscape(name)
+}
+
+// roffEscape escapes backslashes and dashes so they are rendered literally
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	return strings.ReplaceAll(s, "-", `\-`)
+}
+
+// roffText escapes a block of text, guarding lines that would otherwise be read as requests
+func roffText(s string) string {
+	lines := strings.Split(roffEscape(s), "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = `\&` + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
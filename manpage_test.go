@@ -0,0 +1,55 @@
+package clix
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden compares actual with testdata/<name>, rewriting the file when run with -update
+func assertGolden(t *testing.T, name string, actual string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0o644))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), actual)
+}
+
+func TestManPage(t *testing.T) {
+	assertGolden(t, "manpage.golden", ManPage[DocsConfig]("mytool", 1))
+}
+
+func TestManPageTree(t *testing.T) {
+	root := ManCommand{
+		Name:        "mytool",
+		Usage:       "does things",
+		Description: "mytool does things.\n.dot lines are escaped",
+		Commands: []ManCommand{
+			{
+				Name:  "db",
+				Usage: "database commands",
+				Commands: []ManCommand{
+					{
+						Name:  "migrate",
+						Usage: "run migrations",
+						Flags: []ManFlag{
+							{Names: []string{"dry-run", "n"}, Usage: "only print"},
+							{Names: []string{"dir"}, Env: []string{"MIGRATE_DIR"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	assertGolden(t, "manpage_command.golden", ManPageTree[NestedConfig](root, 8))
+}
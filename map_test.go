@@ -0,0 +1,132 @@
+package clix
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Tenant struct {
+	Host string `cli:"host"`
+	DB   struct {
+		Host string `cli:"host" cli-required:"true"`
+		Port int    `cli:"port" cli-default:"5432"`
+	} `cli-prefix:"db-"`
+}
+
+type MultiTenant struct {
+	Tenants map[string]Tenant   `cli-prefix:"tenant-" cli-keys:"tenants" cli-usage:"tenants to serve"`
+	Shards  map[string]*Tenant  `cli-prefix:"shard."`
+	Ignored map[string]struct{} `cli:"-"`
+}
+
+func TestDescribeMap(t *testing.T) {
+	spec := Describe[MultiTenant]()
+	require.Len(t, spec.Maps, 2)
+	m := spec.Maps[0]
+	assert.Equal(t, "Tenants", m.Field)
+	assert.Equal(t, "tenant-", m.Prefix)
+	assert.Equal(t, "tenants", m.Keys)
+	assert.Equal(t, "tenant-acme-", m.ElemPrefix("acme"))
+	assert.Equal(t, "shard.eu.", spec.Maps[1].ElemPrefix("eu"))
+	assert.False(t, spec.IsEmpty())
+
+	_, err := Compile[MultiTenant]()
+	assert.NoError(t, err)
+}
+
+func TestParseMapDiscovered(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+tenant:
+  acme:
+    host: acme.example.com
+    db:
+      host: db.acme
+  acme-corp:
+    db.host: db.acme-corp
+    db.port: 5433
+shard.eu.db.host: db.eu
+`))
+	require.NoError(t, err)
+
+	cfg, err := TryParse[MultiTenant](r)
+	require.NoError(t, err)
+	require.Len(t, cfg.Tenants, 2)
+	assert.Equal(t, "acme.example.com", cfg.Tenants["acme"].Host)
+	assert.Equal(t, "db.acme", cfg.Tenants["acme"].DB.Host)
+	assert.Equal(t, 5432, cfg.Tenants["acme"].DB.Port)
+	assert.Equal(t, "db.acme-corp", cfg.Tenants["acme-corp"].DB.Host)
+	assert.Equal(t, 5433, cfg.Tenants["acme-corp"].DB.Port)
+
+	require.Contains(t, cfg.Shards, "eu")
+	assert.Equal(t, "db.eu", cfg.Shards["eu"].DB.Host)
+}
+
+func TestParseMapKeysFlag(t *testing.T) {
+	ctx := newMockContext()
+	ctx.stringSliceMap["tenants"] = []string{"globex", "acme"}
+	ctx.stringMap["tenant-acme-db-host"] = "db.acme"
+	ctx.stringMap["tenant-globex-db-host"] = "db.globex"
+	ctx.stringMap["tenant-initech-db-host"] = "not listed"
+
+	cfg, err := TryParse[MultiTenant](ctx)
+	require.NoError(t, err)
+	assert.Len(t, cfg.Tenants, 2)
+	assert.Equal(t, "db.globex", cfg.Tenants["globex"].DB.Host)
+	assert.Equal(t, 5432, cfg.Tenants["globex"].DB.Port)
+	assert.Nil(t, cfg.Shards)
+}
+
+func TestValidateMap(t *testing.T) {
+	ctx := newMockContext()
+	ctx.stringSliceMap["tenants"] = []string{"acme", "globex"}
+	ctx.stringMap["tenant-acme-db-host"] = "db.acme"
+
+	_, err := TryParse[MultiTenant](ctx)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, "tenant-globex-db-host", pe.Errors[0].Flag)
+	assert.Equal(t, "Tenants[globex].DB.Host", pe.Errors[0].Field)
+	assert.EqualError(t, err, "--tenant-globex-db-host: required flag is not set")
+}
+
+func TestCompileMapErrors(t *testing.T) {
+	type Instance struct {
+		Port int `cli:"port" cli-default:"eighty"`
+	}
+	type Config struct {
+		Instances map[string]Instance `cli-prefix:"instance-"`
+		Names     []string            `cli:"names" cli-keys:"x"`
+	}
+	_, err := Compile[Config]()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"Names: cli-keys on a field that is not a map of structs",
+		`--instance-<name>-port: invalid cli-default "eighty": strconv.ParseInt: parsing "eighty": invalid syntax`,
+	}, messages)
+}
+
+func TestDiffMap(t *testing.T) {
+	var a, b MultiTenant
+	a.Tenants = map[string]Tenant{"acme": {Host: "a"}}
+	b.Tenants = map[string]Tenant{"acme": {Host: "b"}, "globex": {Host: "g"}}
+	assert.Equal(t, []Change{
+		{Flag: "tenant-acme-host", Field: "Tenants[acme].Host", Old: "a", New: "b"},
+		{Flag: "tenant-globex-host", Field: "Tenants[globex].Host", Old: "", New: "g"},
+	}, Diff(a, b))
+}
+
+func TestDocsMap(t *testing.T) {
+	md := DocsMarkdown[MultiTenant](DocsOptions{})
+	assert.Contains(t, md, "Map: `--tenant-<name>-<flag>` for each instance, the names are listed by `--tenants`")
+	assert.Contains(t, md, "| `--tenant-<name>-db-host` |")
+	assert.Contains(t, md, "`--shard.<name>.host`")
+}
@@ -0,0 +1,167 @@
+package clix
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ToArgs writes cfg back as command line arguments, the flags that Parse reads cfg from, followed by the positional arguments.
+// Flags holding their `cli-default`, or the zero value when there is none, are left out.
+// Values are written in their text form: durations as "1m30s", times with their first `cli-layout`, RFC 3339 by default, ByteSize, Percent, Rate
+// and other text types with their MarshalText. Slices and maps of strings repeat the flag once per element.
+// Slices and maps of structs are written as the indexed flags of their elements, --upstream-0-host, and unions
+// as their discriminator and the flags of the variant.
+// The options are those cfg is parsed with, only WithAutoNames matters.
+// Usage:
+//
+//	args, err := clix.ToArgs(cfg)
+//	cmd := exec.Command("worker", args...)
+func ToArgs[A any](cfg A, opts ...Option) ([]string, error) {
+	o := newOptions(opts)
+	val := reflect.ValueOf(&cfg).Elem()
+	errs := &ParseError{}
+	var args []string
+	appendArgs(val, "", o, &args, errs)
+
+	p := planFor(val.Type(), o)
+	positional, err := positionalArgs(p, val)
+	if err != nil {
+		errs.add("", "", err)
+	}
+	for _, a := range positional {
+		if strings.HasPrefix(a, "-") {
+			// the arguments follow a "--" so that they are not taken as flags
+			args = append(args, "--")
+			break
+		}
+	}
+	return append(args, positional...), errs.orNil()
+}
+
+// appendArgs appends the flags of val, a struct read with prefix, that do not hold their default
+func appendArgs(val reflect.Value, prefix string, o *options, args *[]string, errs *ParseError) {
+	p := planFor(val.Type(), o)
+	p.spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		v := fieldByIndexOrZero(val, f.Index, f.GoType)
+		if isDefault(v, f) {
+			return
+		}
+		name := "--" + prefix + f.Name
+		if v.Kind() == reflect.Bool && v.Bool() {
+			*args = append(*args, name)
+			return
+		}
+		texts, err := flagTexts(v, f)
+		if err != nil {
+			errs.add(prefix+f.Name, f.Field, err)
+			return
+		}
+		for _, text := range texts {
+			*args = append(*args, name+"="+text)
+		}
+	})
+
+	for _, l := range p.lists {
+		list := fieldByIndexOrZero(val, l.Index, l.GoType)
+		for i := 0; i < list.Len(); i++ {
+			if elem := list.Index(i); elem.Kind() != reflect.Ptr || !elem.IsNil() {
+				appendArgs(reflect.Indirect(elem), prefix+l.ElemPrefix(i), o, args, errs)
+			}
+		}
+	}
+	for _, m := range p.maps {
+		instances := fieldByIndexOrZero(val, m.Index, m.GoType)
+		for _, key := range sortedKeys(instances) {
+			if m.Keys != "" {
+				*args = append(*args, "--"+prefix+m.Keys+"="+key.String())
+			}
+			if elem := instances.MapIndex(key); elem.Kind() != reflect.Ptr || !elem.IsNil() {
+				appendArgs(reflect.Indirect(elem), prefix+m.ElemPrefix(key.String()), o, args, errs)
+			}
+		}
+	}
+	for _, u := range p.unions {
+		v, elem, ok := unionValue(u, fieldByIndexOrZero(val, u.Index, u.GoType))
+		if !ok {
+			continue
+		}
+		if v.Name != u.Default {
+			*args = append(*args, "--"+prefix+u.Flag+"="+v.Name)
+		}
+		appendArgs(elem, prefix+v.Prefix, o, args, errs)
+	}
+}
+
+// positionalArgs returns the positional arguments of val, up to the last one that does not hold its default,
+// followed by the remaining arguments
+func positionalArgs(p *Plan, val reflect.Value) ([]string, error) {
+	byPos := make([]string, p.rest)
+	last := -1
+	var rest []string
+	for _, a := range p.args {
+		v := fieldByIndexOrZero(val, a.Index, a.GoType)
+		texts, err := flagTexts(v, a.flagSpec())
+		if err != nil {
+			return nil, &FieldError{Arg: a.Name, Field: a.Field, Err: err}
+		}
+		if a.Position < 0 {
+			rest = texts
+			continue
+		}
+		if len(texts) > 0 {
+			byPos[a.Position] = texts[0]
+		}
+		if !isDefault(v, a.flagSpec()) && a.Position > last {
+			last = a.Position
+		}
+	}
+	if len(rest) > 0 {
+		return append(byPos, rest...), nil
+	}
+	return byPos[:last+1], nil
+}
+
+// isDefault reports whether v, the value of flag f, holds the `cli-default` of f, or the zero value when there is none
+func isDefault(v reflect.Value, f FlagSpec) bool {
+	if f.Default == "" {
+		return v.IsZero() || (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0
+	}
+	d, err := parseTagValue(f, f.Default)
+	return err == nil && equalValues(v, d)
+}
+
+// flagTexts returns the text of each value flag f is given, one per element for slices and maps
+func flagTexts(v reflect.Value, f FlagSpec) ([]string, error) {
+	switch {
+	case isTime(v.Type()) && !(v.Kind() == reflect.Ptr && v.IsNil()):
+		return []string{formatTime(f, reflect.Indirect(v).Interface().(time.Time))}, nil
+	case v.Kind() == reflect.Slice:
+		texts := make([]string, v.Len())
+		for i := range texts {
+			text, err := formatText(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			texts[i] = text
+		}
+		return texts, nil
+	case v.Kind() == reflect.Map:
+		var texts []string
+		for _, key := range sortedKeys(v) {
+			text, err := formatText(v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			texts = append(texts, key.String()+"="+text)
+		}
+		return texts, nil
+	case v.Kind() == reflect.Ptr && v.IsNil():
+		return nil, nil
+	}
+	text, err := formatText(v)
+	if err != nil {
+		return nil, err
+	}
+	return []string{text}, nil
+}
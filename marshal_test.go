@@ -0,0 +1,40 @@
+package clix
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Worker struct {
+	Name    string            `cli:"name"`
+	Port    int               `cli:"port" cli-default:"8080"`
+	Debug   bool              `cli:"debug"`
+	Cache   bool              `cli:"cache" cli-default:"true"`
+	Timeout time.Duration     `cli:"timeout" cli-default:"5s"`
+	Tags    []string          `cli:"tags"`
+	Labels  map[string]string `cli:"labels"`
+	Limits  Limits            `cli-prefix:"limits-"`
+	Src     string            `cli-arg:"0"`
+	Files   []string          `cli-args:"rest"`
+}
+
+func TestToArgsElements(t *testing.T) {
+	var p Proxy
+	p.Upstreams = []Upstream{{Host: "a"}, {Host: "b", Port: 8080}}
+	args, err := ToArgs(p)
+	require.NoError(t, err)
+	assert.Contains(t, args, "--upstream-1-port=8080")
+
+	b := Blobs{Storage: S3Storage{Bucket: "b", Region: "eu-north-1"}, Backup: &FSStorage{Root: "/backup"}}
+	args, err = ToArgs(b)
+	require.NoError(t, err)
+	// the region holds its default and the backup its default variant, fs
+	assert.Equal(t, []string{"--storage=s3", "--s3-bucket=b", "--backup-fs-root=/backup"}, args)
+
+	args, err = ToArgs(Defaults[Worker]())
+	require.NoError(t, err)
+	assert.Empty(t, args)
+}
@@ -0,0 +1,65 @@
+package clix
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NamePolicy derives flag names from Go field names, for fields without a `cli` tag, see WithAutoNames.
+// The plans compiled with a policy are cached by the policy and released with it, a policy created per Parse
+// compiles its plans on every Parse, so a policy should still be created once and reused.
+type NamePolicy struct {
+	name  func(field string) string
+	sep   string
+	plans sync.Map // *Plan per reflect.Type
+}
+
+var (
+	// KebabCase names MaxConns "max-conns", nested structs are prefixed with "name-"
+	KebabCase = &NamePolicy{name: func(field string) string { return joinWords(field, "-") }, sep: "-"}
+	// SnakeCase names MaxConns "max_conns", nested structs are prefixed with "name_"
+	SnakeCase = &NamePolicy{name: func(field string) string { return joinWords(field, "_") }, sep: "_"}
+)
+
+// NameFunc returns a policy naming fields with fn, nested structs are prefixed with their name followed by sep
+func NameFunc(fn func(field string) string, sep string) *NamePolicy {
+	return &NamePolicy{name: fn, sep: sep}
+}
+
+// Name returns the flag name of a field
+func (p *NamePolicy) Name(field string) string {
+	return p.name(field)
+}
+
+// Prefix returns the prefix of the flags of a nested struct field
+func (p *NamePolicy) Prefix(field string) string {
+	return p.name(field) + p.sep
+}
+
+// joinWords lower cases the words of a Go identifier and joins them with sep, "HTTPServer" becomes "http-server".
+// Lower case letters that end in a digit belong to the upper case run before them, "IPv6Addr" becomes "ipv6-addr".
+func joinWords(ident, sep string) string {
+	runes := []rune(ident)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && startsWord(runes[i+1:])) {
+				b.WriteString(sep)
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// startsWord tells whether the upper case letter before rest, ending an upper case run, starts a word:
+// it does when lower case letters follow that do not end in a digit
+func startsWord(rest []rune) bool {
+	n := 0
+	for n < len(rest) && unicode.IsLower(rest[n]) {
+		n++
+	}
+	return n > 0 && (n == len(rest) || !unicode.IsDigit(rest[n]))
+}
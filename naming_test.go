@@ -0,0 +1,146 @@
+package clix
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type AutoConfig struct {
+	MaxConns   int
+	HTTPServer string
+	Timeout    time.Duration
+	Start      time.Time
+	Host       string `cli:"addr"`
+	Internal   string `cli:"-"`
+	Database   struct {
+		DBPort int
+	}
+	Pool struct {
+		Size int
+	} `cli-prefix:"p-"`
+	Flat struct {
+		Verbose bool
+	} `cli-prefix:""`
+	Ignored struct {
+		Name string
+	} `cli:"-"`
+}
+
+func TestJoinWords(t *testing.T) {
+	tests := map[string]string{
+		"MaxConns":   "max-conns",
+		"HTTPServer": "http-server",
+		"DBPort":     "db-port",
+		"ID":         "id",
+		"Port2Use":   "port2-use",
+		"name":       "name",
+		"IPv6Addr":   "ipv6-addr",
+		"HTTP2Port":  "http2-port",
+		"OAuth2":     "oauth2",
+		"X509Cert":   "x509-cert",
+		"APIKey":     "api-key",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, joinWords(in, "-"), in)
+	}
+}
+
+func TestDescribeAutoNames(t *testing.T) {
+	var names []string
+	Describe[AutoConfig](WithAutoNames(KebabCase)).Walk(func(_ SectionSpec, f FlagSpec) {
+		names = append(names, f.Name)
+	})
+	assert.Equal(t, []string{"max-conns", "http-server", "timeout", "start", "addr", "database-db-port", "p-size", "verbose"}, names)
+
+	names = nil
+	Describe[AutoConfig](WithAutoNames(SnakeCase)).Walk(func(_ SectionSpec, f FlagSpec) {
+		names = append(names, f.Name)
+	})
+	assert.Equal(t, []string{"max_conns", "http_server", "timeout", "start", "addr", "database_db_port", "p-size", "verbose"}, names)
+
+	// without the option only tagged fields are flags
+	assert.Len(t, Describe[AutoConfig]().Flags, 1)
+}
+
+func TestParseAutoNames(t *testing.T) {
+	ctx := newMockContext()
+	ctx.intMap["max-conns"] = 10
+	ctx.stringMap["http-server"] = "srv"
+	ctx.stringMap["addr"] = "localhost"
+	ctx.stringMap["internal"] = "not read"
+	ctx.intMap["database-db-port"] = 5432
+	ctx.intMap["p-size"] = 4
+	ctx.boolMap["verbose"] = true
+	ctx.durationMap["timeout"] = time.Second
+
+	cfg := Parse[AutoConfig](ctx, WithAutoNames(KebabCase))
+	assert.Equal(t, 10, cfg.MaxConns)
+	assert.Equal(t, "srv", cfg.HTTPServer)
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Empty(t, cfg.Internal)
+	assert.Equal(t, 5432, cfg.Database.DBPort)
+	assert.Equal(t, 4, cfg.Pool.Size)
+	assert.True(t, cfg.Flat.Verbose)
+	assert.Equal(t, time.Second, cfg.Timeout)
+
+	// plans are cached per policy
+	assert.Zero(t, Parse[AutoConfig](ctx).MaxConns)
+}
+
+func TestAutoNamesCustom(t *testing.T) {
+	upper := NameFunc(strings.ToUpper, ".")
+	defs, err := FlagDefs[AutoConfig](WithAutoNames(upper))
+	require.NoError(t, err)
+	assert.Equal(t, "MAXCONNS", defs[0].Name)
+	assert.Equal(t, "DATABASE.DBPORT", defs[5].Name)
+
+	p, err := Compile[AutoConfig](WithAutoNames(upper))
+	require.NoError(t, err)
+	assert.Equal(t, "DATABASE.DBPORT", p.Spec().Sections[0].Flags[0].Name)
+}
+
+func TestAutoNamesPlanCache(t *testing.T) {
+	count := func(m *sync.Map) int {
+		n := 0
+		m.Range(func(_, _ any) bool { n++; return true })
+		return n
+	}
+	before := count(&plans)
+	for i := 0; i < 3; i++ {
+		// a policy created per call keeps its plans to itself
+		policy := NameFunc(strings.ToUpper, ".")
+		Parse[AutoConfig](newMockContext(), WithAutoNames(policy))
+		assert.Positive(t, count(&policy.plans))
+	}
+	assert.Equal(t, before, count(&plans))
+
+	p1, _ := Compile[AutoConfig](WithAutoNames(KebabCase))
+	p2, _ := Compile[AutoConfig](WithAutoNames(KebabCase))
+	assert.Same(t, p1, p2)
+}
+
+func TestAutoNamesUnsupportedField(t *testing.T) {
+	type Config struct {
+		Name string
+		Hook func()
+	}
+	// the hook gets no automatic name, so Compile, Describe and FlagDefs agree on leaving it out
+	_, err := Compile[Config](WithAutoNames(KebabCase))
+	require.NoError(t, err)
+	assert.Len(t, Describe[Config](WithAutoNames(KebabCase)).Flags, 1)
+	defs, err := FlagDefs[Config](WithAutoNames(KebabCase))
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	assert.Equal(t, "name", defs[0].Name)
+
+	type Tagged struct {
+		Hook func() `cli:"hook"`
+	}
+	_, err = Compile[Tagged](WithAutoNames(KebabCase))
+	assert.EqualError(t, err, "--hook: unsupported type func()")
+}
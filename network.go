@@ -0,0 +1,140 @@
+package clix
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Network address types. *url.URL is read with url.Parse, netip.Addr, netip.AddrPort and netip.Prefix
+// are text types of their own, see isTextType.
+var (
+	_ encoding.TextUnmarshaler = (*HostPort)(nil)
+
+	urlType      = reflect.TypeOf((*url.URL)(nil))
+	hostPortType = reflect.TypeOf(HostPort{})
+)
+
+// HostPort is a host name or IP address and a port, "db.local:5432", "[::1]:8080" or ":8080" for all interfaces.
+// A value without port, "db.local", has port 0, the `cli-port` tag of a field gives such values a default port.
+type HostPort struct {
+	Host string
+	Port uint16
+}
+
+// ParseHostPort parses an address such as "db.local:5432", "10.0.0.1", "[::1]:80" or ":8080".
+// The port is a number, service names are not looked up.
+func ParseHostPort(s string) (HostPort, error) {
+	host, port, err := net.SplitHostPort(s)
+	hasPort := err == nil
+	if !hasPort {
+		// no port, a host name or an IP address, IPv6 ones with or without brackets
+		host = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+		if _, ipErr := netip.ParseAddr(host); ipErr != nil && (strings.ContainsAny(s, ":[]") || host == "") {
+			return HostPort{}, fmt.Errorf("invalid address %q: expected a host and a port, e.g. db.local:5432", s)
+		}
+	}
+	if strings.ContainsAny(host, " /\\@?#") {
+		return HostPort{}, fmt.Errorf("invalid address %q: invalid host %q", s, host)
+	}
+	hp := HostPort{Host: host}
+	if hasPort {
+		n, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return HostPort{}, fmt.Errorf("invalid address %q: invalid port %q", s, port)
+		}
+		hp.Port = uint16(n)
+	}
+	if hp.Host == "" && hp.Port == 0 {
+		return HostPort{}, fmt.Errorf("invalid address %q: expected a host and a port, e.g. db.local:5432", s)
+	}
+	return hp, nil
+}
+
+// String returns the address as given to net.Dial or net.Listen, "db.local:5432", the host alone when the port is 0
+func (h HostPort) String() string {
+	if h.Port == 0 {
+		if strings.Contains(h.Host, ":") {
+			return "[" + h.Host + "]"
+		}
+		return h.Host
+	}
+	return net.JoinHostPort(h.Host, strconv.Itoa(int(h.Port)))
+}
+
+func (h HostPort) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *HostPort) UnmarshalText(text []byte) error {
+	v, err := ParseHostPort(string(text))
+	if err != nil {
+		return err
+	}
+	*h = v
+	return nil
+}
+
+// checkNetworkTags reports `cli-scheme` and `cli-port` tags on fields of other types, and values that do not parse
+func checkNetworkTags(f FlagSpec) error {
+	if len(f.Schemes) > 0 && elemType(f.GoType) != urlType {
+		return errors.New("cli-scheme on a field that is not a *url.URL or a slice of them")
+	}
+	if f.Port != "" {
+		if elemType(f.GoType) != hostPortType {
+			return errors.New("cli-port on a field that is not a clix.HostPort or a slice of them")
+		}
+		if n, err := strconv.ParseUint(f.Port, 10, 16); err != nil || n == 0 {
+			return fmt.Errorf("invalid cli-port %q", f.Port)
+		}
+	}
+	return nil
+}
+
+// resolveAddrs checks the scheme of URLs against `cli-scheme`, and sets the port of HostPorts given without one
+// to `cli-port`, reporting those without port when the field has no `cli-port`
+func resolveAddrs(f FlagSpec, field reflect.Value) error {
+	switch elemType(f.GoType) {
+	case urlType:
+		if len(f.Schemes) == 0 {
+			return nil
+		}
+		for _, v := range elemValues(field) {
+			u := v.Interface().(*url.URL)
+			if u != nil && !contains(f.Schemes, u.Scheme) {
+				return fmt.Errorf("invalid URL %q: scheme %q is not one of %s", u, u.Scheme, strings.Join(f.Schemes, ", "))
+			}
+		}
+	case hostPortType:
+		for _, v := range elemValues(field) {
+			hp := v.Addr().Interface().(*HostPort)
+			if hp.Port != 0 || hp.Host == "" {
+				continue
+			}
+			if f.Port == "" {
+				return fmt.Errorf("invalid address %q: missing port", hp)
+			}
+			port, _ := strconv.ParseUint(f.Port, 10, 16)
+			hp.Port = uint16(port)
+		}
+	}
+	return nil
+}
+
+// elemValues returns the elements of a slice, or the value itself
+func elemValues(v reflect.Value) []reflect.Value {
+	if v.Kind() != reflect.Slice {
+		return []reflect.Value{v}
+	}
+	values := make([]reflect.Value, v.Len())
+	for i := range values {
+		values[i] = v.Index(i)
+	}
+	return values
+}
@@ -0,0 +1,159 @@
+package clix
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Gateway struct {
+	Listen   HostPort         `cli:"listen" cli-default:":8080"`
+	Database HostPort         `cli:"database" cli-port:"5432"`
+	Peers    []HostPort       `cli:"peers" cli-port:"7946"`
+	Upstream *url.URL         `cli:"upstream" cli-scheme:"https,http"`
+	Mirrors  []*url.URL       `cli:"mirrors"`
+	Admin    netip.AddrPort   `cli:"admin"`
+	Bind     netip.Addr       `cli:"bind"`
+	Allow    []netip.Prefix   `cli:"allow"`
+	Resolver []netip.AddrPort `cli:"resolver"`
+}
+
+func TestParseHostPort(t *testing.T) {
+	tests := map[string]HostPort{
+		"db.local:5432": {Host: "db.local", Port: 5432},
+		"db.local":      {Host: "db.local"},
+		"10.0.0.1":      {Host: "10.0.0.1"},
+		"[::1]:80":      {Host: "::1", Port: 80},
+		"::1":           {Host: "::1"},
+		"[::1]":         {Host: "::1"},
+		":8080":         {Port: 8080},
+	}
+	for in, want := range tests {
+		hp, err := ParseHostPort(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, hp, in)
+	}
+
+	assert.Equal(t, "db.local:5432", HostPort{Host: "db.local", Port: 5432}.String())
+	assert.Equal(t, "[::1]:80", HostPort{Host: "::1", Port: 80}.String())
+	assert.Equal(t, "[::1]", HostPort{Host: "::1"}.String())
+	assert.Equal(t, ":8080", HostPort{Port: 8080}.String())
+
+	errs := map[string]string{
+		"":              `invalid address "": expected a host and a port, e.g. db.local:5432`,
+		"db.local:http": `invalid address "db.local:http": invalid port "http"`,
+		"db.local:1e6":  `invalid address "db.local:1e6": invalid port "1e6"`,
+		"a:b:c":         `invalid address "a:b:c": expected a host and a port, e.g. db.local:5432`,
+		"user@db:5432":  `invalid address "user@db:5432": invalid host "user@db"`,
+	}
+	for in, msg := range errs {
+		_, err := ParseHostPort(in)
+		assert.EqualError(t, err, msg, in)
+	}
+}
+
+func TestParseNetwork(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+database: db.local
+peers: [10.0.0.2, "10.0.0.3:8000"]
+upstream: https://api.example.com/v1
+mirrors: [https://a.example, "ftp://b.example"]
+admin: "127.0.0.1:9090"
+bind: "::1"
+allow: [10.0.0.0/8, "fd00::/8"]
+resolver: ["1.1.1.1:53"]
+`))
+	require.NoError(t, err)
+
+	cfg, err := TryParse[Gateway](r)
+	require.NoError(t, err)
+	assert.Equal(t, HostPort{Host: "db.local", Port: 5432}, cfg.Database)
+	assert.Equal(t, []HostPort{{Host: "10.0.0.2", Port: 7946}, {Host: "10.0.0.3", Port: 8000}}, cfg.Peers)
+	require.NotNil(t, cfg.Upstream)
+	assert.Equal(t, "api.example.com", cfg.Upstream.Host)
+	require.Len(t, cfg.Mirrors, 2)
+	assert.Equal(t, "ftp", cfg.Mirrors[1].Scheme)
+	assert.Equal(t, netip.MustParseAddrPort("127.0.0.1:9090"), cfg.Admin)
+	assert.Equal(t, netip.MustParseAddr("::1"), cfg.Bind)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}, cfg.Allow)
+	assert.Equal(t, []netip.AddrPort{netip.MustParseAddrPort("1.1.1.1:53")}, cfg.Resolver)
+}
+
+func TestParseNetworkErrors(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+listen: localhost
+upstream: ftp://files.example.com
+admin: "127.0.0.1"
+allow: [10.0.0.0/33]
+`))
+	require.NoError(t, err)
+
+	_, err = TryParse[Gateway](r)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		`--listen: invalid address "localhost": missing port`,
+		`--upstream: invalid URL "ftp://files.example.com": scheme "ftp" is not one of https, http`,
+		`--admin: not an ip:port`,
+		`--allow: netip.ParsePrefix("10.0.0.0/33"): prefix length out of range`,
+	}, messages)
+}
+
+func TestCompileNetworkTagErrors(t *testing.T) {
+	type Config struct {
+		Host string   `cli:"host" cli-port:"80"`
+		Site string   `cli:"site" cli-scheme:"https"`
+		Addr HostPort `cli:"addr" cli-port:"http"`
+		Home *url.URL `cli:"home" cli-default:"%zz"`
+	}
+	_, err := Compile[Config]()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"--host: cli-port on a field that is not a clix.HostPort or a slice of them",
+		"--site: cli-scheme on a field that is not a *url.URL or a slice of them",
+		`--addr: invalid cli-port "http"`,
+		`--home: invalid cli-default "%zz": parse "%zz": invalid URL escape "%zz"`,
+	}, messages)
+}
+
+func TestNetworkToArgsAndDocs(t *testing.T) {
+	upstream, err := url.Parse("https://api.example.com/v1?x=1")
+	require.NoError(t, err)
+	cfg := Gateway{
+		Listen:   HostPort{Port: 8080},
+		Database: HostPort{Host: "db.local", Port: 5432},
+		Upstream: upstream,
+		Bind:     netip.MustParseAddr("10.0.0.1"),
+		Allow:    []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}
+	args, err := ToArgs(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--database=db.local:5432", "--upstream=https://api.example.com/v1?x=1", "--bind=10.0.0.1", "--allow=10.0.0.0/8",
+	}, args)
+
+	spec := Describe[Gateway]()
+	var types []string
+	for _, f := range spec.Flags {
+		types = append(types, f.Type)
+	}
+	assert.Equal(t, []string{"hostport", "hostport", "[]hostport", "url", "[]url", "addrport", "addr", "[]prefix", "[]addrport"}, types)
+	assert.Equal(t, "Default port: 5432.", flagDescription(spec.Flags[1]))
+	assert.Equal(t, "Schemes: `https`, `http`.", flagDescription(spec.Flags[3]))
+	schema, err := JSONSchema[Gateway]()
+	require.NoError(t, err)
+	assert.Equal(t, "uri", schema.Properties["upstream"].Format)
+}
@@ -0,0 +1,83 @@
+package clix
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Option changes how Parse and the functions built on it read a config
+type Option func(*options)
+
+type options struct {
+	logger       *slog.Logger
+	naming       *NamePolicy
+	extendedTime bool
+	now          func() time.Time
+	stat         func(path string) (fs.FileInfo, error)
+}
+
+// WithLogger sets the logger warnings are written to, such as the use of a deprecated flag name.
+// slog.Default() is used otherwise.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithAutoNames names the fields that have no `cli` tag with the policy, e.g. clix.KebabCase,
+// and prefixes the flags of nested structs without a `cli-prefix` tag with the name of their field.
+// Explicit tags still win and `cli:"-"` excludes a field. Fields of types no flag can set, such as funcs
+// and channels, are skipped by Parse, Describe and FlagDefs alike, while a tag naming one is reported by Compile.
+//
+// The same option must be given wherever the struct is read, to Parse as well as to FlagDefs, the adapters or Describe.
+func WithAutoNames(policy *NamePolicy) Option {
+	return func(o *options) {
+		o.naming = policy
+	}
+}
+
+// WithExtendedTime reads durations with days and weeks, "7d" or "2w3d", and times relative to now,
+// "now-1h", "today", "yesterday 08:00" or "tomorrow+2h", besides their usual syntax.
+// Such values are read as text, so FlagDefs and the adapters create string flags for durations and times when given this option.
+// Relative times are taken in the `cli-tz` zone of the field, or the zone of the clock, see WithClock.
+func WithExtendedTime() Option {
+	return func(o *options) {
+		o.extendedTime = true
+	}
+}
+
+// WithClock sets the clock relative times are computed from, time.Now otherwise. It is meant for tests.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithStat sets the function `cli-path` checks look paths up with, os.Stat otherwise.
+// Tests can check paths against an fs.FS:
+//
+//	clix.WithStat(func(path string) (fs.FileInfo, error) { return fs.Stat(fsys, strings.TrimPrefix(path, "/")) })
+func WithStat(stat func(path string) (fs.FileInfo, error)) Option {
+	return func(o *options) {
+		o.stat = stat
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+	if o.now == nil {
+		o.now = time.Now
+	}
+	if o.stat == nil {
+		o.stat = os.Stat
+	}
+	return o
+}
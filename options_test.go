@@ -0,0 +1,101 @@
+package clix
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type RenamedConfig struct {
+	Addr string `cli:"listen-addr,addr|deprecated=bind" cli-required:"true"`
+	DB   struct {
+		Port int `cli:"port|deprecated=port-number,pg-port"`
+	} `cli-prefix:"db-"`
+}
+
+func TestParseNameTag(t *testing.T) {
+	nt, err := parseNameTag("listen-addr, addr|deprecated=bind,old-bind")
+	require.NoError(t, err)
+	assert.Equal(t, nameTag{name: "listen-addr", aliases: []string{"addr"}, deprecated: []string{"bind", "old-bind"}}, nt)
+
+	_, err = parseNameTag("addr|hidden")
+	assert.EqualError(t, err, `unknown option "hidden" in cli tag "addr|hidden"`)
+	_, err = parseNameTag("|deprecated=x")
+	assert.Error(t, err)
+}
+
+func TestDescribeNames(t *testing.T) {
+	spec := Describe[RenamedConfig]()
+	assert.Equal(t, "listen-addr", spec.Flags[0].Name)
+	assert.Equal(t, []string{"addr"}, spec.Flags[0].Aliases)
+	assert.Equal(t, []string{"bind"}, spec.Flags[0].Deprecated)
+	assert.Equal(t, []string{"db-port", "db-port-number", "db-pg-port"}, spec.Sections[0].Flags[0].Names())
+}
+
+func TestParseNames(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{ReplaceAttr: dropTime}))
+
+	ctx := setMockContext{cliContextMock: newMockContext(), set: map[string]bool{}}
+	ctx.stringMap["bind"] = "0.0.0.0:80"
+	ctx.set["bind"] = true
+
+	cfg, err := TryParse[RenamedConfig](ctx, WithLogger(logger))
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:80", cfg.Addr)
+	assert.Equal(t, "level=WARN msg=\"deprecated flag name used\" flag=bind use=listen-addr\n", logs.String())
+
+	// the main name comes first, equal values do not conflict
+	logs.Reset()
+	ctx.stringMap["listen-addr"] = "0.0.0.0:80"
+	ctx.set["listen-addr"] = true
+	cfg, err = TryParse[RenamedConfig](ctx, WithLogger(logger))
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:80", cfg.Addr)
+
+	ctx.stringMap["addr"] = ":8080"
+	ctx.set["addr"] = true
+	cfg, err = TryParse[RenamedConfig](ctx, WithLogger(logger))
+	assert.EqualError(t, err, "--listen-addr: --addr conflicts with --listen-addr")
+	assert.Equal(t, "0.0.0.0:80", cfg.Addr)
+
+	// Parse ignores conflicts
+	assert.Equal(t, "0.0.0.0:80", Parse[RenamedConfig](ctx, WithLogger(logger)).Addr)
+}
+
+func TestParseNamesWithoutIsSet(t *testing.T) {
+	ctx := newMockContext()
+	ctx.intMap["db-pg-port"] = 5432
+
+	cfg, err := TryParse[RenamedConfig](ctx, WithLogger(slog.New(slog.DiscardHandler)))
+	assert.EqualError(t, err, "--listen-addr: required flag is not set")
+	assert.Equal(t, 5432, cfg.DB.Port)
+}
+
+func TestCompileNameTagErrors(t *testing.T) {
+	type Config struct {
+		Addr string `cli:"addr|alias=a"`
+	}
+	_, err := Compile[Config]()
+	assert.EqualError(t, err, `Addr: unknown option "alias" in cli tag "addr|alias=a"`)
+}
+
+func dropTime(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+func TestDocsNames(t *testing.T) {
+	md := DocsMarkdown[RenamedConfig](DocsOptions{})
+	assert.Contains(t, md, "| `--listen-addr`, `--addr` |")
+	assert.Contains(t, md, "Deprecated names: `--bind`.")
+
+	man := ManPage[RenamedConfig]("tool", 1)
+	assert.Contains(t, man, "\\fB\\-\\-listen\\-addr\\fR, \\fB\\-\\-addr\\fR=\\fIstring\\fR")
+	assert.Contains(t, man, "Deprecated names: \\-\\-bind")
+}
@@ -0,0 +1,156 @@
+package clix
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// pathRules are the options of a `cli-path` tag
+type pathRules struct {
+	expand   bool // "~" is the home directory
+	abs      bool // the field is set to the absolute path
+	exists   bool
+	dir      bool // exists and is a directory
+	file     bool // exists and is a regular file
+	writable bool // is writable, or can be created in a writable directory
+}
+
+// parsePathTag parses the options of a `cli-path` tag, e.g. []string{"expand", "abs", "exists"}
+func parsePathTag(opts []string) (pathRules, error) {
+	var r pathRules
+	for _, opt := range opts {
+		switch opt {
+		case "expand":
+			r.expand = true
+		case "abs":
+			r.abs = true
+		case "exists":
+			r.exists = true
+		case "dir":
+			r.dir = true
+		case "file":
+			r.file = true
+		case "writable":
+			r.writable = true
+		default:
+			return r, fmt.Errorf("unknown cli-path option %q, expected expand, abs, exists, dir, file or writable", opt)
+		}
+	}
+	if r.dir && r.file {
+		return r, errors.New("cli-path can not require both dir and file")
+	}
+	return r, nil
+}
+
+// checkPathTag reports `cli-path` tags that can not be used with the type of f
+func checkPathTag(f FlagSpec) error {
+	if len(f.Path) == 0 {
+		return nil
+	}
+	if elemType(f.GoType).Kind() != reflect.String || isTextType(elemType(f.GoType)) {
+		return errors.New("cli-path on a field that is not a string or a slice of strings")
+	}
+	_, err := parsePathTag(f.Path)
+	return err
+}
+
+// resolvePaths rewrites and checks the path, or paths, held by field following the `cli-path` tag of f.
+// Relative paths read from a config file are taken relative to its directory, see ConfigPathReader.
+func resolvePaths(f FlagSpec, field reflect.Value, c ContextReader, o *options) error {
+	if len(f.Path) == 0 {
+		return nil
+	}
+	rules, err := parsePathTag(f.Path)
+	if err != nil {
+		return err
+	}
+	base := ""
+	if r, ok := c.(ConfigPathReader); ok && r.Path() != "" {
+		base = filepath.Dir(r.Path())
+	}
+	if field.Kind() == reflect.Slice {
+		for i := 0; i < field.Len(); i++ {
+			if err := resolvePath(rules, field.Index(i), base, o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return resolvePath(rules, field, base, o)
+}
+
+// resolvePath rewrites and checks the path held by the string value v, empty paths are left alone
+func resolvePath(rules pathRules, v reflect.Value, base string, o *options) error {
+	path := v.String()
+	if path == "" {
+		return nil
+	}
+	if rules.expand && (path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator))) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("expanding %q: %w", path, err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) && base != "" {
+		path = filepath.Join(base, path)
+	}
+	if rules.abs {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("resolving %q: %w", path, err)
+		}
+		path = abs
+	}
+	v.SetString(path)
+
+	if rules.exists || rules.dir || rules.file {
+		info, err := o.stat(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("%s does not exist", path)
+		case err != nil:
+			return err
+		case rules.dir && !info.IsDir():
+			return fmt.Errorf("%s is not a directory", path)
+		case rules.file && !info.Mode().IsRegular():
+			return fmt.Errorf("%s is not a file", path)
+		}
+	}
+	if rules.writable {
+		return checkWritable(path, o)
+	}
+	return nil
+}
+
+// checkWritable fails when path exists without write permission, or when it does not exist and its directory
+// is missing or not writable. Only the permission bits are looked at, the process may still be denied access.
+func checkWritable(path string, o *options) error {
+	info, err := o.stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		dir := filepath.Dir(path)
+		info, err = o.stat(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s can not be created, %s does not exist", path, dir)
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || info.Mode().Perm()&0o222 == 0 {
+			return fmt.Errorf("%s can not be created, %s is not a writable directory", path, dir)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0o222 == 0 {
+		return fmt.Errorf("%s is not writable", path)
+	}
+	return nil
+}
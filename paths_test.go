@@ -0,0 +1,136 @@
+package clix
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Volumes struct {
+	Data    string   `cli:"data" cli-path:"expand,exists,dir"`
+	Config  string   `cli:"config" cli-path:"file"`
+	Out     string   `cli:"out" cli-path:"writable"`
+	Include []string `cli:"include" cli-path:"abs"`
+	Cache   string   `cli:"cache" cli-path:"expand"`
+}
+
+// volumesFS is the file system the Volumes tests check paths against
+var volumesFS = fstest.MapFS{
+	"srv/data":         {Mode: fs.ModeDir | 0o755},
+	"srv/app.yaml":     {Mode: 0o644},
+	"srv/readonly":     {Mode: fs.ModeDir | 0o555},
+	"srv/readonly.log": {Mode: 0o444},
+}
+
+func statVolumes(path string) (fs.FileInfo, error) {
+	return fs.Stat(volumesFS, strings.TrimPrefix(filepath.ToSlash(path), "/"))
+}
+
+func TestParsePaths(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	ctx := newMockContext()
+	ctx.stringMap["data"] = "/srv/data"
+	ctx.stringMap["config"] = "/srv/app.yaml"
+	ctx.stringMap["out"] = "/srv/data/out.log"
+	ctx.stringSliceMap["include"] = []string{"conf.d", "/etc/app"}
+	ctx.stringMap["cache"] = "~/cache"
+
+	cfg, err := TryParse[Volumes](ctx, WithStat(statVolumes))
+	require.NoError(t, err)
+	assert.Equal(t, Volumes{
+		Data:    "/srv/data",
+		Config:  "/srv/app.yaml",
+		Out:     "/srv/data/out.log",
+		Include: []string{filepath.Join(wd, "conf.d"), "/etc/app"},
+		Cache:   "/home/u/cache",
+	}, cfg)
+
+	// paths are rewritten by Parse as well, only the checks need TryParse to be reported
+	ctx.stringMap["data"] = "~/missing"
+	assert.Equal(t, "/home/u/missing", Parse[Volumes](ctx, WithStat(statVolumes)).Data)
+}
+
+func TestParsePathsErrors(t *testing.T) {
+	tests := map[string]struct {
+		flag, value string
+		msg         string
+	}{
+		"missing":       {"data", "/srv/nope", "--data: /srv/nope does not exist"},
+		"not a dir":     {"data", "/srv/app.yaml", "--data: /srv/app.yaml is not a directory"},
+		"not a file":    {"config", "/srv/data", "--config: /srv/data is not a file"},
+		"read-only":     {"out", "/srv/readonly.log", "--out: /srv/readonly.log is not writable"},
+		"read-only dir": {"out", "/srv/readonly/out.log", "--out: /srv/readonly/out.log can not be created, /srv/readonly is not a writable directory"},
+		"no dir":        {"out", "/srv/logs/out.log", "--out: /srv/logs/out.log can not be created, /srv/logs does not exist"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := newMockContext()
+			ctx.stringMap[tt.flag] = tt.value
+			_, err := TryParse[Volumes](ctx, WithStat(statVolumes))
+			assert.EqualError(t, err, tt.msg)
+		})
+	}
+}
+
+func TestParsePathsFromFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "data"), 0o755))
+	path := filepath.Join(dir, "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("data: data\nconfig: app.yaml\ninclude: [conf.d]\nout: /var/log/app.log\n"), 0o644))
+
+	r, err := ReadFile(path)
+	require.NoError(t, err)
+	cfg, err := TryParse[Volumes](r, WithStat(func(p string) (fs.FileInfo, error) {
+		if p == "/var/log/app.log" || p == "/var/log" {
+			return os.Stat(dir)
+		}
+		return os.Stat(p)
+	}))
+	require.NoError(t, err)
+	// relative paths are taken relative to the directory of the file
+	assert.Equal(t, filepath.Join(dir, "data"), cfg.Data)
+	assert.Equal(t, path, cfg.Config)
+	assert.Equal(t, []string{filepath.Join(dir, "conf.d")}, cfg.Include)
+	assert.Equal(t, "/var/log/app.log", cfg.Out)
+}
+
+func TestParsePathArgs(t *testing.T) {
+	type Cat struct {
+		Files []string `cli-args:"rest" cli-path:"exists"`
+	}
+	_, err := TryParse[Cat](withArgs("/srv/app.yaml", "/srv/nope"), WithStat(statVolumes))
+	assert.EqualError(t, err, "<files>: /srv/nope does not exist")
+}
+
+func TestCompilePathTagErrors(t *testing.T) {
+	type Config struct {
+		Port  int    `cli:"port" cli-path:"exists"`
+		Both  string `cli:"both" cli-path:"dir,file"`
+		Typo  string `cli:"typo" cli-path:"exist"`
+		Paths string `cli:"paths" cli-path:"expand,abs,exists,dir,writable"`
+	}
+	_, err := Compile[Config]()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"--port: cli-path on a field that is not a string or a slice of strings",
+		"--both: cli-path can not require both dir and file",
+		`--typo: unknown cli-path option "exist", expected expand, abs, exists, dir, file or writable`,
+	}, messages)
+
+	assert.Equal(t, "Path: `expand`, `exists`, `dir`.", flagDescription(Describe[Volumes]().Flags[0]))
+}
@@ -0,0 +1,693 @@
+package clix
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Plan is the compiled form of a config struct: the flags it is read from and how each field is set.
+// Plans are built once per type and cached, Parse and AssignValueToCliFields use them implicitly.
+type Plan struct {
+	typ    reflect.Type
+	spec   SectionSpec
+	fields []planField
+	lists  []ListSpec
+	maps   []MapSpec
+	unions []UnionSpec
+	args   []ArgSpec
+	rest   int     // index of the first argument read by the `cli-args` field
+	ptrs   [][]int // index paths of the struct pointer fields holding sections, deepest first
+	err    error
+}
+
+type planField struct {
+	index      []int
+	field      string   // Go field path
+	names      []string // full name first, then aliases and deprecated names
+	deprecated int      // index of the first deprecated name in names
+	set        setter
+	flag       FlagSpec
+}
+
+// plans caches a *Plan per reflect.Type, for types read without a naming policy.
+// The plans of a policy are cached by the policy, see NamePolicy.
+var plans sync.Map
+
+// Compile returns the plan for A, reporting every tag problem found in the struct.
+// Parse ignores such problems, and skips the fields they concern, so calling Compile at startup,
+// or in a test, is the way to surface them early.
+// The errors are returned as a *ParseError.
+// Usage:
+//
+//	func TestConfig(t *testing.T) {
+//		if _, err := clix.Compile[Config](); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func Compile[A any](opts ...Option) (*Plan, error) {
+	return CompileType(reflect.TypeOf((*A)(nil)).Elem(), opts...)
+}
+
+// CompileType is the non-generic version of Compile
+func CompileType(t reflect.Type, opts ...Option) (*Plan, error) {
+	o := newOptions(opts)
+	p := planFor(t, o)
+	return p, p.errors(o)
+}
+
+// errors returns the errors of p together with those of the plans of its elements, see elemErrors
+func (p *Plan) errors(o *options) error {
+	errs := &ParseError{}
+	if p.err != nil {
+		errs.add("", "", p.err)
+	}
+	elemErrors(p, o, map[reflect.Type]bool{p.typ: true}, errs)
+	return errs.orNil()
+}
+
+// elemErrors adds the errors of the plans of the list elements, map instances and union variants of p, and of their own,
+// with the flag names and field paths of the elements, e.g. --upstream-N-port and Upstreams[].Port,
+// or --tenant-<name>-db-host and Tenants[<name>].DB.Host
+func elemErrors(p *Plan, o *options, seen map[reflect.Type]bool, errs *ParseError) {
+	for _, l := range p.lists {
+		addElemErrors(indirect(l.GoType.Elem()), l.elemPrefix("N"), l.Field+"[]", o, seen, errs)
+	}
+	for _, m := range p.maps {
+		addElemErrors(indirect(m.GoType.Elem()), m.ElemPrefix("<name>"), m.Field+"[<name>]", o, seen, errs)
+	}
+	for _, u := range p.unions {
+		for _, v := range u.Variants {
+			addElemErrors(indirect(v.GoType), v.Prefix, u.Field+"("+v.Name+")", o, seen, errs)
+		}
+	}
+}
+
+func addElemErrors(t reflect.Type, prefix, path string, o *options, seen map[reflect.Type]bool, errs *ParseError) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	elem := planFor(t, o)
+	inner := &ParseError{}
+	if elem.err != nil {
+		inner.add("", "", elem.err)
+	}
+	elemErrors(elem, o, seen, inner)
+	for _, fe := range inner.Errors {
+		flag := fe.Flag
+		if flag != "" {
+			flag = prefix + flag
+		}
+		errs.Errors = append(errs.Errors, &FieldError{Flag: flag, Field: joinPath(path, fe.Field), Err: fe.Err})
+	}
+}
+
+// Type returns the struct type the plan was compiled for
+func (p *Plan) Type() reflect.Type {
+	return p.typ
+}
+
+// Spec returns the description of the flags of the plan
+func (p *Plan) Spec() SectionSpec {
+	return p.spec
+}
+
+func planFor(t reflect.Type, o *options) *Plan {
+	cache := &plans
+	if o.naming != nil {
+		cache = &o.naming.plans
+	}
+	if p, ok := cache.Load(t); ok {
+		return p.(*Plan)
+	}
+	p, _ := cache.LoadOrStore(t, compilePlan(t, o.naming))
+	return p.(*Plan)
+}
+
+func compilePlan(t reflect.Type, naming *NamePolicy) *Plan {
+	p := &Plan{typ: t}
+	if t.Kind() != reflect.Struct {
+		p.err = fmt.Errorf("clix: %s is not a struct", t)
+		return p
+	}
+
+	errs := &ParseError{}
+	checkTags(t, "", errs)
+
+	p.spec = describeType(t, naming)
+	collisions(p.spec, errs)
+	p.spec.WalkLists(func(_ SectionSpec, l ListSpec) {
+		p.lists = append(p.lists, l)
+	})
+	p.spec.WalkMaps(func(_ SectionSpec, m MapSpec) {
+		p.maps = append(p.maps, m)
+	})
+	p.spec.WalkUnions(func(_ SectionSpec, u UnionSpec) {
+		if len(u.Variants) == 0 {
+			errs.add(u.Flag, u.Field, fmt.Errorf("no variants registered for %s", u.GoType))
+		}
+		if u.Default != "" {
+			if _, ok := u.Variant(u.Default); !ok {
+				errs.add(u.Flag, u.Field, fmt.Errorf("invalid cli-default %q: not one of %s", u.Default, strings.Join(u.Names(), ", ")))
+			}
+		}
+		p.unions = append(p.unions, u)
+	})
+	p.spec.WalkArgs(func(_ SectionSpec, a ArgSpec) {
+		if compileSetter(a.GoType) == nil {
+			errs.Errors = append(errs.Errors, &FieldError{Arg: a.Name, Field: a.Field, Err: fmt.Errorf("unsupported type %s", a.GoType)})
+			return
+		}
+		if err := checkFlagTags(a.flagSpec()); err != nil {
+			errs.Errors = append(errs.Errors, &FieldError{Arg: a.Name, Field: a.Field, Err: err})
+		}
+		p.args = append(p.args, a)
+	})
+	p.rest = checkArgs(p.args, errs)
+	p.ptrs = sectionPointers(t, nil)
+	p.spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		set := compileSetter(f.GoType)
+		if set == nil {
+			errs.add(f.Name, f.Field, fmt.Errorf("unsupported type %s", f.GoType))
+			return
+		}
+		if err := checkFlagTags(f); err != nil {
+			errs.add(f.Name, f.Field, err)
+		}
+		p.fields = append(p.fields, planField{
+			index:      f.Index,
+			field:      f.Field,
+			names:      f.Names(),
+			deprecated: 1 + len(f.Aliases),
+			set:        set,
+			flag:       f,
+		})
+	})
+
+	p.err = errs.orNil()
+	return p
+}
+
+// assign sets every field of val, a value of the plan's type, from the reader.
+// The fields are always set, the error reports flags given under several names with conflicting values.
+//
+// Nil struct pointers on the way to a field are allocated, and set back to nil when none of their fields got a value.
+func (p *Plan) assign(val reflect.Value, prefix string, c ContextReader, o *options) error {
+	wasNil := make([]bool, len(p.ptrs))
+	for i, index := range p.ptrs {
+		ptr, err := val.FieldByIndexErr(index)
+		wasNil[i] = err != nil || ptr.IsNil()
+	}
+
+	errs := &ParseError{}
+	for _, f := range p.fields {
+		field := fieldByIndexAlloc(val, f.index)
+		if len(f.names) == 1 {
+			if err := f.setValue(c, prefix+f.names[0], field, o); err != nil {
+				errs.add(prefix+f.names[0], f.field, err)
+			}
+			continue
+		}
+		if err := f.assignNames(c, prefix, field, o); err != nil {
+			errs.add(prefix+f.names[0], f.field, err)
+		}
+	}
+	for _, l := range p.lists {
+		if err := assignList(l, val, prefix, c, o); err != nil {
+			errs.add("", "", err)
+		}
+	}
+	for _, m := range p.maps {
+		if err := assignMap(m, val, prefix, c, o); err != nil {
+			errs.add("", "", err)
+		}
+	}
+	for _, u := range p.unions {
+		if err := assignUnion(u, val, prefix, c, o); err != nil {
+			errs.add("", "", err)
+		}
+	}
+	if len(p.args) > 0 {
+		args := readArgs(c)
+		for _, a := range p.args {
+			if err := assignArg(a, val, args, p.rest, c, o); err != nil {
+				errs.Errors = append(errs.Errors, err)
+			}
+		}
+	}
+
+	for i, index := range p.ptrs {
+		if ptr, err := val.FieldByIndexErr(index); wasNil[i] && err == nil && !ptr.IsNil() && ptr.Elem().IsZero() {
+			ptr.SetZero()
+		}
+	}
+	return errs.orNil()
+}
+
+// assignList sets a slice of structs from the indexed flags of its elements, or when there are none,
+// from the JSON or YAML array held by the flag of the list. The element structs are assigned with their own plan.
+func assignList(l ListSpec, val reflect.Value, prefix string, c ContextReader, o *options) error {
+	plan := planFor(indirect(l.GoType.Elem()), o)
+	errs := &ParseError{}
+	var list reflect.Value
+
+	if indexes := listIndexes(c, prefix, l); len(indexes) > 0 {
+		list = reflect.MakeSlice(l.GoType, len(indexes), len(indexes))
+		for i, index := range indexes {
+			elem, elemPrefix := listElem(list, i), prefix+l.ElemPrefix(index)
+			if err := plan.assign(elem, elemPrefix, c, o); err != nil {
+				errs.add("", "", err)
+			}
+			plan.applyDefaults(elem, elemPrefix, c)
+		}
+	} else if text := c.String(prefix + l.FlagName()); l.FlagName() != "" && text != "" {
+		var docs []map[string]any
+		if err := yaml.Unmarshal([]byte(text), &docs); err != nil {
+			return &FieldError{Flag: prefix + l.FlagName(), Field: l.Field, Err: fmt.Errorf("invalid list: %w", err)}
+		}
+		list = reflect.MakeSlice(l.GoType, len(docs), len(docs))
+		for i, doc := range docs {
+			r := &FileReader{values: map[string]any{}}
+			flatten(r.values, "", doc)
+			elem := listElem(list, i)
+			if err := plan.assign(elem, "", r, o); err != nil {
+				errs.add("", "", err)
+			}
+			plan.applyDefaults(elem, "", r)
+			if err := r.Err(); err != nil {
+				errs.add(prefix+l.FlagName(), fmt.Sprintf("%s[%d]", l.Field, i), err)
+			}
+		}
+	}
+
+	field := fieldByIndexAlloc(val, l.Index)
+	if list.IsValid() {
+		field.Set(list)
+	} else {
+		field.SetZero()
+	}
+	return errs.orNil()
+}
+
+// assignMap sets a map of structs with one instance per name found by mapKeys,
+// each assigned with the plan of the struct from the flags following the prefix of the map and the name.
+func assignMap(m MapSpec, val reflect.Value, prefix string, c ContextReader, o *options) error {
+	t := indirect(m.GoType.Elem())
+	plan := planFor(t, o)
+	field := fieldByIndexAlloc(val, m.Index)
+	keys := mapKeys(c, prefix, m, plan)
+	if len(keys) == 0 {
+		field.SetZero()
+		return nil
+	}
+
+	errs := &ParseError{}
+	instances := reflect.MakeMapWithSize(m.GoType, len(keys))
+	for _, key := range keys {
+		elem := reflect.New(t)
+		elemPrefix := prefix + m.ElemPrefix(key)
+		if err := plan.assign(elem.Elem(), elemPrefix, c, o); err != nil {
+			errs.add("", "", err)
+		}
+		plan.applyDefaults(elem.Elem(), elemPrefix, c)
+		if m.GoType.Elem().Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+		instances.SetMapIndex(reflect.ValueOf(key).Convert(m.GoType.Key()), elem)
+	}
+	field.Set(instances)
+	return errs.orNil()
+}
+
+// mapKeys returns the instance names of the map, sorted: the values of its cli-keys flag when given,
+// otherwise the names found among the flag names the reader lists, and the names of its cli-default when none is found.
+// A name is what lies between the prefix of the map and a flag of the instance struct, e.g. acme-corp in
+// tenant-acme-corp-db-host. The longest flag that matches wins, so tenant-acme-db-host is the db-host of acme
+// rather than the host of acme-db.
+func mapKeys(c ContextReader, prefix string, m MapSpec, plan *Plan) []string {
+	seen := map[string]bool{}
+	var keys []string
+	add := func(key string) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	if m.Keys != "" {
+		for _, key := range c.StringSlice(prefix + m.Keys) {
+			add(key)
+		}
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			return keys
+		}
+	}
+
+	var names []string
+	if r, ok := c.(FlagNamesReader); ok {
+		names = r.FlagNames()
+	}
+	isSet, canTell := c.(IsSetReader)
+	var suffixes []string
+	for _, f := range plan.fields {
+		for _, name := range f.names {
+			// any separator normalizes to "-"
+			suffixes = append(suffixes, normalizeKey("-"+name))
+		}
+	}
+	sort.Slice(suffixes, func(i, j int) bool { return len(suffixes[i]) > len(suffixes[j]) })
+
+	mapPrefix := normalizeKey(prefix + m.Prefix)
+	for _, name := range names {
+		norm := normalizeKey(name)
+		if !strings.HasPrefix(norm, mapPrefix) || canTell && !isSet.IsSet(name) {
+			continue
+		}
+		for _, suffix := range suffixes {
+			if len(norm) > len(mapPrefix)+len(suffix) && strings.HasSuffix(norm, suffix) {
+				// normalizing keeps the length, the name is cut from the flag as given
+				add(name[len(mapPrefix) : len(name)-len(suffix)])
+				break
+			}
+		}
+	}
+	if len(keys) == 0 {
+		for _, key := range m.Default {
+			add(key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// applyDefaults sets the fields of val that were not given to their `cli-default`.
+// Flags carry the defaults of the fields of a config, the elements of a list have no flags so they get them here.
+func (p *Plan) applyDefaults(val reflect.Value, prefix string, c ContextReader) {
+	isSet, canTell := c.(IsSetReader)
+	p.spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		if f.Default == "" {
+			return
+		}
+		if canTell {
+			for _, name := range f.Names() {
+				if isSet.IsSet(prefix + name) {
+					return
+				}
+			}
+		} else if !fieldByIndexOrZero(val, f.Index, f.GoType).IsZero() {
+			return
+		}
+		if def, err := parseTagValue(f, f.Default); err == nil {
+			fieldByIndexAlloc(val, f.Index).Set(def)
+		}
+	})
+}
+
+// listIndexes returns the element indexes of the list given to the reader, in ascending order.
+// An element is given when one of the flag names listed by the reader starts with the prefix of the list,
+// an index and a separator, e.g. upstream-3-host. The indexes need not be contiguous.
+// Names are compared the way FileReader normalizes keys, so upstream.3.host is found for the prefix "upstream-".
+func listIndexes(c ContextReader, prefix string, l ListSpec) []int {
+	r, ok := c.(FlagNamesReader)
+	if !ok {
+		return nil
+	}
+	isSet, canTell := c.(IsSetReader)
+	listPrefix := normalizeKey(prefix + l.Prefix)
+
+	seen := map[int]bool{}
+	var indexes []int
+	for _, name := range r.FlagNames() {
+		rest, ok := strings.CutPrefix(normalizeKey(name), listPrefix)
+		if !ok {
+			continue
+		}
+		digits, _, ok := strings.Cut(rest, "-")
+		index, err := strconv.Atoi(digits)
+		if !ok || err != nil || strconv.Itoa(index) != digits || seen[index] || canTell && !isSet.IsSet(name) {
+			continue
+		}
+		seen[index] = true
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// listElem returns the struct of the element i of list, allocating it for slices of pointers
+func listElem(list reflect.Value, i int) reflect.Value {
+	elem := list.Index(i)
+	if elem.Kind() == reflect.Ptr {
+		elem.Set(reflect.New(elem.Type().Elem()))
+		elem = elem.Elem()
+	}
+	return elem
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// sectionPointers returns the index paths of the struct pointer fields of t that are read as sections, deepest first
+func sectionPointers(t reflect.Type, index []int) [][]int {
+	var ptrs [][]int
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		st, ok := sectionType(fieldType.Type)
+		if !ok || !fieldType.IsExported() || fieldType.Tag.Get("cli") != "" || fieldType.Tag.Get("cli-cmd") != "" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		ptrs = append(ptrs, sectionPointers(st, fieldIndex)...)
+		if fieldType.Type.Kind() == reflect.Ptr {
+			ptrs = append(ptrs, fieldIndex)
+		}
+	}
+	return ptrs
+}
+
+// fieldByIndexAlloc is reflect.Value.FieldByIndex, allocating the nil struct pointers on the way
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldByIndexOrZero is reflect.Value.FieldByIndex, returning the zero value of t when there is a nil pointer on the way
+func fieldByIndexOrZero(v reflect.Value, index []int, t reflect.Type) reflect.Value {
+	field, err := v.FieldByIndexErr(index)
+	if err != nil {
+		return reflect.Zero(t)
+	}
+	return field
+}
+
+// assignNames sets a field that has aliases or deprecated names from the first of its names that was given.
+// A name is given when the reader reports it as set, or, for readers that can not tell, when its value is not zero.
+// Using a deprecated name is logged as a warning.
+func (f planField) assignNames(c ContextReader, prefix string, field reflect.Value, o *options) error {
+	isSet, canTell := c.(IsSetReader)
+
+	var first reflect.Value
+	var firstName string
+	var err error
+	for i, n := range f.names {
+		name := prefix + n
+		v := reflect.New(field.Type()).Elem()
+		if setErr := f.setValue(c, name, v, o); setErr != nil {
+			return setErr
+		}
+		if canTell && !isSet.IsSet(name) || !canTell && v.IsZero() {
+			continue
+		}
+
+		if i >= f.deprecated {
+			o.logger.Warn("deprecated flag name used", "flag", name, "use", prefix+f.names[0])
+		}
+		if !first.IsValid() {
+			first, firstName = v, name
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(v.Interface(), first.Interface()) {
+			err = fmt.Errorf("--%s conflicts with --%s", name, firstName)
+		}
+	}
+
+	if !first.IsValid() {
+		// nothing given, the default of the main name applies
+		if setErr := f.setValue(c, prefix+f.names[0], field, o); setErr != nil {
+			return setErr
+		}
+		return err
+	}
+	field.Set(first)
+	return err
+}
+
+// setValue sets field from the flag name and finishes it, see finishValue
+func (f planField) setValue(c ContextReader, name string, field reflect.Value, o *options) error {
+	if err := f.read(c, name, field, o); err != nil {
+		return err
+	}
+	return finishValue(f.flag, field, c, o)
+}
+
+// finishValue applies the tags that rewrite or check a value once it is set, `cli-path`, `cli-scheme` and `cli-port`
+func finishValue(f FlagSpec, field reflect.Value, c ContextReader, o *options) error {
+	if err := resolvePaths(f, field, c, o); err != nil {
+		return err
+	}
+	return resolveAddrs(f, field)
+}
+
+// read sets field from the flag name. Durations and times are read from their text when readsText,
+// falling back to the setter when there is none, or when the reader holds a timestamp the text is no form of.
+func (f planField) read(c ContextReader, name string, field reflect.Value, o *options) error {
+	if !readsText(f.flag, o) {
+		return f.set(c, name, field)
+	}
+	s := c.String(name)
+	if s == "" {
+		return f.set(c, name, field)
+	}
+	v, err := parseValue(f.flag, s, o)
+	if err != nil {
+		if isSet, ok := c.(IsSetReader); c.Timestamp(name) != nil || ok && !isSet.IsSet(name) {
+			return f.set(c, name, field)
+		}
+		return err
+	}
+	field.Set(v)
+	return nil
+}
+
+// checkTags reports tags that are misplaced, i.e. tags that Parse would silently ignore
+func checkTags(t reflect.Type, path string, errs *ParseError) {
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		fieldPath := joinPath(path, fieldType.Name)
+		tag := fieldType.Tag.Get("cli")
+		_, hasPrefix := fieldType.Tag.Lookup("cli-prefix")
+
+		if !fieldType.IsExported() {
+			if tag != "" {
+				errs.add("", fieldPath, errors.New("cli tag on unexported field"))
+			}
+			continue
+		}
+
+		if cmd := fieldType.Tag.Get("cli-cmd"); cmd != "" {
+			ft := fieldType.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct {
+				errs.add("", fieldPath, errors.New("cli-cmd on a field that is not a struct"))
+			}
+			continue
+		}
+		st, isSection := sectionType(fieldType.Type)
+		if fieldType.Tag.Get("cli-keys") != "" && (tag != "" || !isMap(fieldType.Type)) {
+			errs.add("", fieldPath, errors.New("cli-keys on a field that is not a map of structs"))
+		}
+		if err := checkArgTag(fieldType); err != nil {
+			errs.add("", fieldPath, err)
+		}
+		if err := checkTimeTags(fieldType); err != nil {
+			errs.add("", fieldPath, err)
+		}
+		_, isUnion := fieldType.Tag.Lookup("cli-union")
+		if isUnion && (tag != "" || fieldType.Type.Kind() != reflect.Interface) {
+			errs.add("", fieldPath, errors.New("cli-union on a field that is not an interface"))
+		}
+
+		if tag != "" {
+			if _, err := parseNameTag(tag); err != nil {
+				errs.add("", fieldPath, err)
+			}
+		}
+
+		switch {
+		case isUnion:
+			// the prefix of a union applies to its variants, which are checked by their own plans, see elemErrors
+		case tag != "" && hasPrefix:
+			errs.add(tag, fieldPath, errors.New("cli-prefix has no effect on a field with a cli tag"))
+		case tag == "" && (isList(fieldType.Type) || isMap(fieldType.Type)):
+			// the element type is checked by its own plan, see elemErrors
+		case tag == "" && hasPrefix && !isSection:
+			errs.add("", fieldPath, errors.New("cli-prefix on a field that is not a struct"))
+		case tag == "" && isSection:
+			checkTags(st, fieldPath, errs)
+		}
+	}
+}
+
+// checkFlagTags reports tag values of a flag that can not be used with its type
+func checkFlagTags(f FlagSpec) error {
+	if err := checkPathTag(f); err != nil {
+		return err
+	}
+	if err := checkNetworkTags(f); err != nil {
+		return err
+	}
+	if f.Default != "" {
+		if _, err := parseTagValue(f, f.Default); err != nil {
+			return fmt.Errorf("invalid cli-default %q: %w", f.Default, err)
+		}
+	}
+	for _, bound := range []string{f.Min, f.Max} {
+		if bound == "" {
+			continue
+		}
+		if err := checkBoundTag(f.GoType, bound); err != nil {
+			return err
+		}
+	}
+	for _, e := range f.Enum {
+		if _, err := parseText(elemType(f.GoType), e); err != nil {
+			return fmt.Errorf("invalid cli-oneof value %q: %w", e, err)
+		}
+	}
+	return nil
+}
+
+func checkBoundTag(t reflect.Type, bound string) error {
+	var err error
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		_, err = parseDuration(bound, true)
+	case isTextType(t):
+		// a bound is written like a value, cli-max:"10MiB", and compared as the number underneath
+		if !isNumber(t) {
+			return fmt.Errorf("invalid bound %q: %s has no order", bound, t)
+		}
+		_, err = parseText(t, bound)
+	case t.Kind() == reflect.String, t.Kind() == reflect.Slice, t.Kind() == reflect.Map:
+		_, err = strconv.Atoi(bound)
+	default:
+		_, err = strconv.ParseFloat(bound, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid bound %q for %s: %w", bound, t, err)
+	}
+	return nil
+}
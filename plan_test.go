@@ -0,0 +1,151 @@
+package clix
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	p, err := Compile[NestedConfig]()
+	require.NoError(t, err)
+	assert.Equal(t, "NestedConfig", p.Type().Name())
+	assert.Len(t, p.fields, 5)
+	assert.Equal(t, []string{"db-host"}, p.fields[1].names)
+	assert.Equal(t, []int{1, 0}, p.fields[1].index)
+
+	// Plans are cached per type
+	again, _ := Compile[NestedConfig]()
+	assert.Same(t, p, again)
+}
+
+func TestCompileTagErrors(t *testing.T) {
+	type Config struct {
+		Port    int           `cli:"port" cli-default:"eighty"`
+		Ratio   float64       `cli:"ratio" cli-max:"one"`
+		Timeout time.Duration `cli:"timeout" cli-min:"5"`
+		Mode    int           `cli:"mode" cli-oneof:"1,two"`
+		Ch      chan int      `cli:"ch"`
+		Host    string        `cli:"host" cli-prefix:"db-"`
+		Name    string        `cli-prefix:"db-"`
+		Cmd     int           `cli-cmd:"cmd"`
+		hidden  string        `cli:"hidden"`
+		Nested  struct {
+			Bad []bool `cli:"bad"`
+		} `cli-prefix:"nested-"`
+	}
+
+	_, err := Compile[Config]()
+	require.Error(t, err)
+
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	messages := []string{}
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"--host: cli-prefix has no effect on a field with a cli tag",
+		"Name: cli-prefix on a field that is not a struct",
+		"Cmd: cli-cmd on a field that is not a struct",
+		"hidden: cli tag on unexported field",
+		`--port: invalid cli-default "eighty": strconv.ParseInt: parsing "eighty": invalid syntax`,
+		`--ratio: invalid bound "one" for float64: strconv.ParseFloat: parsing "one": invalid syntax`,
+		`--timeout: invalid bound "5" for time.Duration: invalid duration "5", expected e.g. 90s, 1h30m, 7d or 2w`,
+		`--mode: invalid cli-oneof value "two": strconv.ParseInt: parsing "two": invalid syntax`,
+		"--ch: unsupported type chan int",
+		"--nested-bad: unsupported type []bool",
+	}, messages)
+
+	// Parse still sets the fields it can
+	ctx := newMockContext()
+	ctx.intMap["port"] = 80
+	ctx.stringMap["host"] = "localhost"
+	config := Parse[Config](ctx)
+	assert.Equal(t, 80, config.Port)
+	assert.Equal(t, "localhost", config.Host)
+}
+
+func TestCompileNotStruct(t *testing.T) {
+	_, err := Compile[int]()
+	assert.EqualError(t, err, "clix: int is not a struct")
+}
+
+func TestParseConcurrent(t *testing.T) {
+	type Config struct {
+		Name string `cli:"name"`
+		Sub  struct {
+			Port int `cli:"port"`
+		} `cli-prefix:"sub-"`
+	}
+	ctx := newMockContext()
+	ctx.stringMap["name"] = "app"
+	ctx.intMap["sub-port"] = 80
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				config := Parse[Config](ctx)
+				assert.Equal(t, 80, config.Sub.Port)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAssignValueToCliFieldsPrefix(t *testing.T) {
+	ctx := newMockContext()
+	ctx.stringMap["x-db-host"] = "prefixed"
+
+	var config NestedConfig
+	AssignValueToCliFields(&config, "x-", ctx)
+	assert.Equal(t, "prefixed", config.Database.Host)
+}
+
+func BenchmarkParse(b *testing.B) {
+	ctx := newMockContext()
+	ctx.stringMap["name"] = "my-app"
+	ctx.durationMap["timeout"] = time.Minute
+	ctx.boolMap["enabled"] = true
+	ctx.stringMap["db-host"] = "db.example.com"
+	ctx.intMap["db-port"] = 3306
+	ctx.stringSliceMap["db-replicas"] = []string{"replica1", "replica2"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Parse[MixedConfig](ctx)
+	}
+}
+
+func BenchmarkParseAllTypes(b *testing.B) {
+	type Config struct {
+		BasicConfig
+		TimeConfig
+		SliceConfig
+		NestedConfig
+	}
+	ctx := newMockContext()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Parse[Config](ctx)
+	}
+}
+
+func BenchmarkTryParse(b *testing.B) {
+	ctx := newMockContext()
+	ctx.stringMap["name"] = "app"
+	ctx.stringMap["server-host"] = "example.com"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = TryParse[ValidatedConfig](ctx)
+	}
+}
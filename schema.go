@@ -0,0 +1,296 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchemaDraft is the dialect produced by JSONSchema
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the strings accepted by time.ParseDuration
+const durationPattern = `^[-+]?((\d+(\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h))+$|^0$`
+
+// extendedDurationPattern matches the durations accepted with WithExtendedTime, which adds days and weeks
+const extendedDurationPattern = `^[-+]?((\d+(\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h|d|w))+$|^0$`
+
+// Schema is a JSON Schema (draft 2020-12) document, or a subschema of one.
+// Only the keywords clix produces are included.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema describing a config file for A.
+// Properties are keyed by the `cli` tag and nested structs with a `cli-prefix` become nested objects,
+// keyed by the prefix without its trailing separator, e.g. `cli-prefix:"db-"` becomes "db".
+// A section whose key is also the key of a flag is an error. The options are those given to Parse,
+// with WithExtendedTime durations may be given in days and weeks and times may be relative.
+// Usage:
+//
+//	s, err := clix.JSONSchema[Config]()
+//	b, _ := json.MarshalIndent(s, "", "  ")
+func JSONSchema[A any](opts ...Option) (*Schema, error) {
+	return JSONSchemaType(reflect.TypeOf((*A)(nil)).Elem(), opts...)
+}
+
+// JSONSchemaType is the non-generic version of JSONSchema
+func JSONSchemaType(t reflect.Type, opts ...Option) (*Schema, error) {
+	tree, err := buildKeyTree(DescribeType(t))
+	if err != nil {
+		return nil, err
+	}
+	s := objectSchema(tree, newOptions(opts))
+	s.Schema = JSONSchemaDraft
+	s.Title = t.Name()
+	return s, nil
+}
+
+func objectSchema(n *keyNode, o *options) *Schema {
+	s := &Schema{
+		Type:        "object",
+		Description: n.usage,
+		Properties:  map[string]*Schema{},
+	}
+	for _, f := range n.flags {
+		s.Properties[f.Key] = flagSchema(f, o)
+		if f.Required {
+			s.Required = appendUnique(s.Required, f.Key)
+		}
+	}
+	for _, c := range n.children {
+		s.Properties[c.key] = objectSchema(c, o)
+		if c.hasRequired() {
+			s.Required = appendUnique(s.Required, c.key)
+		}
+	}
+	return s
+}
+
+func flagSchema(f FlagSpec, o *options) *Schema {
+	s := typeSchema(f.GoType)
+	s.Description = f.Usage
+	if f.Default != "" {
+		s.Default = schemaValue(f.GoType, f.Default)
+	}
+
+	target := s
+	if s.Type == "array" && s.Items != nil {
+		target = s.Items
+	}
+	if len(f.Layouts) > 0 || o.extendedTime && isTime(f.GoType) {
+		// a time in its `cli-layout`, or a relative one, is no date-time
+		target.Format = ""
+	}
+	if o.extendedTime && target.Pattern == durationPattern {
+		target.Pattern = extendedDurationPattern
+	}
+	for _, e := range f.Enum {
+		target.Enum = append(target.Enum, schemaValue(elemType(f.GoType), e))
+	}
+
+	setBound := func(bound string, num **float64, length **int, items **int) {
+		if bound == "" {
+			return
+		}
+		switch s.Type {
+		case "integer", "number":
+			if v, err := strconv.ParseFloat(bound, 64); err == nil {
+				*num = &v
+			}
+		case "string":
+			if v, err := strconv.Atoi(bound); err == nil {
+				*length = &v
+			}
+		case "array":
+			if v, err := strconv.Atoi(bound); err == nil {
+				*items = &v
+			}
+		}
+	}
+	setBound(f.Min, &s.Minimum, &s.MinLength, &s.MinItems)
+	setBound(f.Max, &s.Maximum, &s.MaxLength, &s.MaxItems)
+	return s
+}
+
+// typeSchema maps a Go type to the schema type of its text representation
+func typeSchema(t reflect.Type) *Schema {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return &Schema{Type: "string", Pattern: durationPattern}
+	case reflect.TypeOf(time.Time{}), reflect.PointerTo(reflect.TypeOf(time.Time{})):
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t {
+	case urlType:
+		return &Schema{Type: "string", Format: "uri"}
+	case regexpType:
+		return &Schema{Type: "string", Format: "regex"}
+	}
+	if isTextType(t) {
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	}
+	return &Schema{}
+}
+
+// schemaValue converts a textual tag value to the JSON value matching the type
+func schemaValue(t reflect.Type, s string) any {
+	if t.Kind() == reflect.Slice {
+		var list []any
+		for _, v := range splitList(s) {
+			list = append(list, schemaValue(t.Elem(), v))
+		}
+		return list
+	}
+	if isTextType(t) {
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			return s
+		}
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	}
+	return s
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Slice {
+		return t.Elem()
+	}
+	return t
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// keyNode is a section of a structured config file, one per `cli-prefix`.
+// Nested structs without a prefix are merged into their parent since their flags share its namespace.
+type keyNode struct {
+	key      string // object key, the prefix without trailing separators
+	usage    string
+	flags    []FlagSpec
+	children []*keyNode
+}
+
+// buildKeyTree returns the key tree of sec, a section with the key of a flag beside it is an error
+func buildKeyTree(sec SectionSpec) (*keyNode, error) {
+	root := &keyNode{usage: sec.Usage}
+	root.add(sec, sec.Prefix)
+	return root, root.checkKeys()
+}
+
+// checkKeys reports the first section of n, or of its sections, that has the key of a flag beside it
+func (n *keyNode) checkKeys() error {
+	for _, c := range n.children {
+		for _, f := range n.flags {
+			if f.Key == c.key {
+				return fmt.Errorf("key %q of the flag --%s is also the key of a section", f.Key, f.Name)
+			}
+		}
+		if err := c.checkKeys(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *keyNode) add(sec SectionSpec, prefix string) {
+	n.flags = append(n.flags, sec.Flags...)
+	for _, sub := range sec.Sections {
+		key := strings.TrimRight(strings.TrimPrefix(sub.Prefix, prefix), "-_.")
+		if key == "" {
+			n.add(sub, sub.Prefix)
+			continue
+		}
+		child := n.child(key)
+		if child.usage == "" {
+			child.usage = sub.Usage
+		}
+		child.add(sub, sub.Prefix)
+	}
+}
+
+func (n *keyNode) child(key string) *keyNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	c := &keyNode{key: key}
+	n.children = append(n.children, c)
+	return c
+}
+
+func (n *keyNode) hasRequired() bool {
+	for _, f := range n.flags {
+		if f.Required {
+			return true
+		}
+	}
+	for _, c := range n.children {
+		if c.hasRequired() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package clix
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	s, err := JSONSchema[DocsConfig]()
+	require.NoError(t, err)
+
+	assert.Equal(t, JSONSchemaDraft, s.Schema)
+	assert.Equal(t, "DocsConfig", s.Title)
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, []string{"mode"}, s.Required)
+
+	host := s.Properties["host"]
+	assert.Equal(t, "string", host.Type)
+	assert.Equal(t, "localhost", host.Default)
+	assert.Equal(t, "Address to bind to.", host.Description)
+
+	port := s.Properties["port"]
+	assert.Equal(t, "integer", port.Type)
+	assert.Equal(t, int64(8080), port.Default)
+	assert.Equal(t, 1.0, *port.Minimum)
+	assert.Equal(t, 65535.0, *port.Maximum)
+
+	assert.Equal(t, []any{"dev", "prod"}, s.Properties["mode"].Enum)
+
+	timeout := s.Properties["timeout"]
+	assert.Equal(t, "string", timeout.Type)
+	assert.NotEmpty(t, timeout.Pattern)
+
+	db := s.Properties["db"]
+	require.NotNil(t, db)
+	assert.Equal(t, "object", db.Type)
+	assert.Equal(t, "Connection settings.", db.Description)
+	assert.Equal(t, "array", db.Properties["replicas"].Type)
+	assert.Equal(t, "string", db.Properties["replicas"].Items.Type)
+	assert.Equal(t, "integer", db.Properties["pool"].Properties["size"].Type)
+}
+
+func TestJSONSchemaTypes(t *testing.T) {
+	type Config struct {
+		Count   uint              `cli:"count"`
+		Ratio   float64           `cli:"ratio" cli-min:"0" cli-max:"1"`
+		Debug   bool              `cli:"debug" cli-default:"true"`
+		At      *time.Time        `cli:"at"`
+		Ports   []int             `cli:"ports" cli-oneof:"80,443" cli-max:"2" cli-default:"80,443"`
+		Name    string            `cli:"name" cli-min:"3"`
+		Labels  map[string]string `cli:"labels"`
+		Flatten struct {
+			Inner string `cli:"inner" cli-required:"true"`
+		}
+		Nested struct {
+			Deep struct {
+				Value time.Duration `cli:"value"`
+			} `cli-prefix:"deep-"`
+		} `cli-prefix:"nested_"`
+	}
+	s, err := JSONSchema[Config]()
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.0, *s.Properties["count"].Minimum)
+	assert.Equal(t, "number", s.Properties["ratio"].Type)
+	assert.Equal(t, 1.0, *s.Properties["ratio"].Maximum)
+	assert.Equal(t, true, s.Properties["debug"].Default)
+	assert.Equal(t, "date-time", s.Properties["at"].Format)
+	assert.Equal(t, []any{int64(80), int64(443)}, s.Properties["ports"].Items.Enum)
+	assert.Equal(t, []any{int64(80), int64(443)}, s.Properties["ports"].Default)
+	assert.Equal(t, 2, *s.Properties["ports"].MaxItems)
+	assert.Equal(t, 3, *s.Properties["name"].MinLength)
+	assert.Equal(t, "string", s.Properties["labels"].AdditionalProperties.Type)
+
+	// Sections without a prefix share the parent namespace
+	assert.Equal(t, "string", s.Properties["inner"].Type)
+	assert.Equal(t, []string{"inner"}, s.Required)
+
+	// Prefixes are nested relative to their parent
+	deep := s.Properties["nested"].Properties["deep"]
+	require.NotNil(t, deep)
+	assert.Equal(t, "string", deep.Properties["value"].Type)
+
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"$schema":"https://json-schema.org/draft/2020-12/schema"`)
+}
+
+func TestJSONSchemaDurationPattern(t *testing.T) {
+	re := regexp.MustCompile(durationPattern)
+	for _, d := range []string{"0", "1s", "1h30m", "1.5h", "300ms", "-2m", "1µs"} {
+		assert.True(t, re.MatchString(d), d)
+	}
+	for _, d := range []string{"", "1", "1d", "h", "1s1"} {
+		assert.False(t, re.MatchString(d), d)
+	}
+}
+
+func TestJSONSchemaExtendedTime(t *testing.T) {
+	s, err := JSONSchema[Retention]()
+	require.NoError(t, err)
+	assert.Equal(t, durationPattern, s.Properties["keep"].Pattern)
+	assert.Equal(t, "date-time", s.Properties["since"].Format)
+
+	s, err = JSONSchema[Retention](WithExtendedTime())
+	require.NoError(t, err)
+	re := regexp.MustCompile(s.Properties["keep"].Pattern)
+	for _, d := range []string{"7d", "2w3d", "1.5d", "1h30m", "0"} {
+		assert.True(t, re.MatchString(d), d)
+	}
+	assert.False(t, re.MatchString("7x"))
+	// relative times such as now-1h are no date-time
+	assert.Empty(t, s.Properties["since"].Format)
+}
+
+func TestJSONSchemaKeyCollision(t *testing.T) {
+	type Config struct {
+		DB  string `cli:"db"`
+		DB2 struct {
+			Host string `cli:"host"`
+		} `cli-prefix:"db-"`
+	}
+	_, err := JSONSchema[Config]()
+	assert.EqualError(t, err, `key "db" of the flag --db is also the key of a section`)
+	_, err = Template[Config](FormatYAML)
+	assert.EqualError(t, err, `key "db" of the flag --db is also the key of a section`)
+}
@@ -0,0 +1,196 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TemplateFormat is a config file format that Template can render.
+type TemplateFormat string
+
+const (
+	FormatDotenv TemplateFormat = "dotenv"
+	FormatYAML   TemplateFormat = "yaml"
+	FormatTOML   TemplateFormat = "toml"
+)
+
+// Template renders a commented sample config file for A.
+// Each key carries its usage text as a comment, defaults are filled in and required keys are marked.
+// Keys without a default that are not required are commented out.
+//
+// Dotenv keys are the first `cli-env` of the flag, or the flag name in upper snake case,
+// YAML and TOML keys are nested by `cli-prefix` the same way as JSONSchema.
+// Usage:
+//
+//	tmpl, err := clix.Template[Config](clix.FormatYAML)
+func Template[A any](format TemplateFormat) (string, error) {
+	return TemplateType(reflect.TypeOf((*A)(nil)).Elem(), format)
+}
+
+// TemplateType is the non-generic version of Template
+func TemplateType(t reflect.Type, format TemplateFormat) (string, error) {
+	spec := DescribeType(t)
+
+	var b strings.Builder
+	switch format {
+	case FormatDotenv:
+		writeDotenvSection(&b, spec)
+	case FormatYAML, FormatTOML:
+		tree, err := buildKeyTree(spec)
+		if err != nil {
+			return "", err
+		}
+		if format == FormatYAML {
+			writeYAMLNode(&b, tree, 0)
+		} else {
+			writeTOMLNode(&b, tree, nil)
+		}
+	default:
+		return "", fmt.Errorf("unknown template format %q", format)
+	}
+	return strings.TrimLeft(b.String(), "\n"), nil
+}
+
+func writeDotenvSection(b *strings.Builder, sec SectionSpec) {
+	if sec.Name != "" && len(sec.Flags) > 0 {
+		fmt.Fprintf(b, "\n# %s\n", strings.ReplaceAll(sec.Field, ".", " "))
+		if sec.Usage != "" {
+			writeComments(b, "", []string{sec.Usage})
+		}
+	}
+	for _, f := range sec.Flags {
+		b.WriteString("\n")
+		writeComments(b, "", templateComments(f))
+
+		key := envName(f.Name)
+		if len(f.Env) > 0 {
+			key = f.Env[0]
+		}
+		writeTemplateKey(b, "", key+"=", f, f.Default)
+	}
+	for _, sub := range sec.Sections {
+		writeDotenvSection(b, sub)
+	}
+}
+
+func writeYAMLNode(b *strings.Builder, n *keyNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, f := range n.flags {
+		b.WriteString("\n")
+		writeComments(b, indent, templateComments(f))
+		writeTemplateKey(b, indent, f.Key+": ", f, yamlValue(f))
+	}
+	for _, c := range n.children {
+		b.WriteString("\n")
+		if c.usage != "" {
+			writeComments(b, indent, []string{c.usage})
+		}
+		b.WriteString(indent + c.key + ":\n")
+		writeYAMLNode(b, c, depth+1)
+	}
+}
+
+func writeTOMLNode(b *strings.Builder, n *keyNode, path []string) {
+	if len(path) > 0 {
+		b.WriteString("\n")
+		if n.usage != "" {
+			writeComments(b, "", []string{n.usage})
+		}
+		b.WriteString("[" + strings.Join(path, ".") + "]\n")
+	}
+	for _, f := range n.flags {
+		b.WriteString("\n")
+		writeComments(b, "", templateComments(f))
+		writeTemplateKey(b, "", f.Key+" = ", f, tomlValue(f))
+	}
+	for _, c := range n.children {
+		writeTOMLNode(b, c, append(append([]string{}, path...), c.key))
+	}
+}
+
+// writeTemplateKey writes "key value", commenting it out when it neither has a default nor is required
+func writeTemplateKey(b *strings.Builder, indent, key string, f FlagSpec, value string) {
+	switch {
+	case f.Default != "":
+		b.WriteString(indent + key + value + "\n")
+	case f.Required:
+		b.WriteString(strings.TrimRight(indent+key+value, " ") + "\n")
+	default:
+		b.WriteString(strings.TrimRight(indent+"# "+key, " ") + "\n")
+	}
+}
+
+func templateComments(f FlagSpec) []string {
+	var lines []string
+	if f.Usage != "" {
+		lines = append(lines, f.Usage)
+	}
+	if len(f.Enum) > 0 {
+		lines = append(lines, "One of: "+strings.Join(f.Enum, ", "))
+	}
+	if f.Required {
+		lines = append(lines, "Required.")
+	}
+	return lines
+}
+
+func writeComments(b *strings.Builder, indent string, lines []string) {
+	for _, l := range lines {
+		for _, part := range strings.Split(l, "\n") {
+			b.WriteString(strings.TrimRight(indent+"# "+part, " ") + "\n")
+		}
+	}
+}
+
+// yamlValue renders the default of f as a YAML value, or an empty string when there is none
+func yamlValue(f FlagSpec) string {
+	if f.Default == "" {
+		if f.Required && f.GoType.Kind() == reflect.String {
+			return `""`
+		}
+		return ""
+	}
+	return literalValue(schemaValue(f.GoType, f.Default))
+}
+
+// tomlValue renders the default of f as a TOML value, required keys without default get the zero value of their type
+func tomlValue(f FlagSpec) string {
+	if f.Default != "" {
+		return literalValue(schemaValue(f.GoType, f.Default))
+	}
+	switch typeSchema(f.GoType).Type {
+	case "integer":
+		return "0"
+	case "number":
+		return "0.0"
+	case "boolean":
+		return "false"
+	case "array":
+		return "[]"
+	case "object":
+		return "{}"
+	}
+	return `""`
+}
+
+// literalValue renders v in the syntax shared by YAML flow style and TOML
+func literalValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = literalValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
+// envName converts a flag name to an environment variable name, "db-host" becomes "DB_HOST"
+func envName(flag string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(flag))
+}
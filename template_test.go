@@ -0,0 +1,54 @@
+package clix
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type TemplateConfig struct {
+	Host     string        `cli:"host" cli-env:"HOST,APP_HOST" cli-default:"localhost" cli-usage:"Address to bind to."`
+	Port     int           `cli:"port" cli-default:"8080"`
+	Mode     string        `cli:"mode" cli-oneof:"dev,prod" cli-required:"true"`
+	Timeout  time.Duration `cli:"timeout"`
+	Debug    bool          `cli:"debug" cli-default:"false" cli-usage:"Verbose logging\nto stderr"`
+	Weights  []int         `cli:"weights" cli-default:"1,2"`
+	Database struct {
+		Name string `cli:"name" cli-usage:"database name"`
+		Pool struct {
+			Size int `cli:"size" cli-default:"4"`
+		} `cli-prefix:"pool-"`
+	} `cli-prefix:"db-" cli-usage:"Connection settings."`
+}
+
+func TestTemplate(t *testing.T) {
+	for _, format := range []TemplateFormat{FormatDotenv, FormatYAML, FormatTOML} {
+		t.Run(string(format), func(t *testing.T) {
+			tmpl, err := Template[TemplateConfig](format)
+			require.NoError(t, err)
+			assertGolden(t, "template."+string(format)+".golden", tmpl)
+		})
+	}
+}
+
+func TestTemplateYAMLIsValid(t *testing.T) {
+	tmpl, err := Template[TemplateConfig](FormatYAML)
+	require.NoError(t, err)
+
+	var parsed map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(tmpl), &parsed))
+	assert.Equal(t, "localhost", parsed["host"])
+	assert.Equal(t, 8080, parsed["port"])
+	assert.Equal(t, "", parsed["mode"])
+	assert.Equal(t, false, parsed["debug"])
+	assert.Equal(t, []any{1, 2}, parsed["weights"])
+	assert.NotContains(t, parsed, "timeout")
+}
+
+func TestTemplateUnknownFormat(t *testing.T) {
+	_, err := Template[TemplateConfig]("ini")
+	assert.Error(t, err)
+}
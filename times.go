@@ -0,0 +1,240 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// isTime reports whether t is time.Time or *time.Time
+func isTime(t reflect.Type) bool {
+	return t == timeType || t == reflect.PointerTo(timeType)
+}
+
+// namedLayouts are the layouts of the time package that `cli-layout` accepts by name
+var namedLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"Kitchen":     time.Kitchen,
+}
+
+// splitLayouts splits a `cli-layout` tag on "|", layouts may hold commas, and resolves the names of namedLayouts
+func splitLayouts(tag string) []string {
+	var layouts []string
+	for _, layout := range strings.Split(tag, "|") {
+		if layout = strings.TrimSpace(layout); layout == "" {
+			continue
+		}
+		if named, ok := namedLayouts[layout]; ok {
+			layout = named
+		}
+		layouts = append(layouts, layout)
+	}
+	return layouts
+}
+
+// layoutsOf returns the layouts of a time flag, RFC 3339 when it has no `cli-layout`
+func layoutsOf(f FlagSpec) []string {
+	if len(f.Layouts) == 0 {
+		return []string{time.RFC3339}
+	}
+	return f.Layouts
+}
+
+// locationOf returns the `cli-tz` zone of a flag, nil when it has none
+func locationOf(f FlagSpec) (*time.Location, error) {
+	if f.TZ == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(f.TZ)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cli-tz %q: %w", f.TZ, err)
+	}
+	return loc, nil
+}
+
+// checkTimeTags reports `cli-layout` and `cli-tz` on fields that are not times, and zones that do not load
+func checkTimeTags(fieldType reflect.StructField) error {
+	_, hasLayout := fieldType.Tag.Lookup("cli-layout")
+	tz, hasTZ := fieldType.Tag.Lookup("cli-tz")
+	switch {
+	case (hasLayout || hasTZ) && !isTime(fieldType.Type):
+		return fmt.Errorf("cli-layout and cli-tz on a field that is not a time.Time")
+	case hasTZ:
+		_, err := locationOf(FlagSpec{TZ: tz})
+		return err
+	}
+	return nil
+}
+
+// readsText reports whether the flag of a duration or time field is read from its text rather than with
+// Duration or Timestamp, because of WithExtendedTime or its `cli-layout` and `cli-tz` tags
+func readsText(f FlagSpec, o *options) bool {
+	switch {
+	case f.GoType == durationType:
+		return o.extendedTime
+	case isTime(f.GoType):
+		return o.extendedTime || len(f.Layouts) > 0 || f.TZ != ""
+	}
+	return false
+}
+
+// parseValue converts the text of flag f as given by a user, with the syntax the options and the time tags allow
+func parseValue(f FlagSpec, s string, o *options) (reflect.Value, error) {
+	switch {
+	case f.GoType == durationType:
+		d, err := parseDuration(s, o.extendedTime)
+		return reflect.ValueOf(d), err
+	case isTime(f.GoType):
+		loc, err := locationOf(f)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		t, err := parseTime(s, layoutsOf(f), loc, o.extendedTime, o.now)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if f.GoType.Kind() == reflect.Ptr {
+			return reflect.ValueOf(&t), nil
+		}
+		return reflect.ValueOf(t), nil
+	}
+	return parseText(f.GoType, s)
+}
+
+// parseTagValue converts a value written in a tag, `cli-default`, `cli-min` or `cli-max`, of flag f.
+// Durations may use days and weeks, and times the layouts and zone of the flag, whatever the options.
+// Relative times are not allowed, a default is fixed when the flags are created.
+// URLs must have one of the schemes of the flag, and addresses get its default port.
+func parseTagValue(f FlagSpec, s string) (reflect.Value, error) {
+	v, err := parseValue(f, s, &options{extendedTime: f.GoType == durationType})
+	if err != nil {
+		return v, err
+	}
+	// resolveAddrs sets ports, which needs an addressable value
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	return cp, resolveAddrs(f, cp)
+}
+
+// dayUnits matches the day and week units of extended durations, the units of time.ParseDuration hold no d or w
+var dayUnits = regexp.MustCompile(`([0-9]*\.?[0-9]+)([dw])`)
+
+// parseDuration parses a duration, "1h30m", and when extended, with days and weeks, "7d", "2w" or "1d12h"
+func parseDuration(s string, extended bool) (time.Duration, error) {
+	text := s
+	if extended {
+		text = dayUnits.ReplaceAllStringFunc(s, func(m string) string {
+			n, _ := strconv.ParseFloat(m[:len(m)-1], 64)
+			if m[len(m)-1] == 'w' {
+				n *= 7
+			}
+			return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+		})
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		if extended {
+			return 0, fmt.Errorf("invalid duration %q, expected e.g. 90s, 1h30m, 7d or 2w", s)
+		}
+		return 0, err
+	}
+	return d, nil
+}
+
+// relativeTime matches the relative times of WithExtendedTime: a reference, an offset and a time of day
+var relativeTime = regexp.MustCompile(`^(now|today|yesterday|tomorrow)(?:\s*([+-])\s*([0-9.][0-9a-z.µ]*))?(?:\s+([0-9]{1,2}):([0-9]{2})(?::([0-9]{2}))?)?$`)
+
+// parseTime parses s with the first layout that matches, in loc when the layout has no zone (UTC when loc is nil).
+// When relative, s may also be a time relative to now, see WithExtendedTime.
+func parseTime(s string, layouts []string, loc *time.Location, relative bool, now func() time.Time) (time.Time, error) {
+	if relative {
+		if m := relativeTime.FindStringSubmatch(s); m != nil {
+			return relativeTo(m, loc, now())
+		}
+	}
+	in := loc
+	if in == nil {
+		in = time.UTC
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, in); err == nil {
+			return t, nil
+		}
+	}
+	expected := make([]string, len(layouts))
+	for i, layout := range layouts {
+		expected[i] = strconv.Quote(layout)
+	}
+	if relative {
+		expected = append(expected, `a relative time such as "now-1h", "today" or "yesterday 08:00"`)
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected %s", s, strings.Join(expected, " or "))
+}
+
+// relativeTo computes the time matched by relativeTime, days start at midnight in loc, or in the zone of now
+func relativeTo(m []string, loc *time.Location, now time.Time) (time.Time, error) {
+	if loc != nil {
+		now = now.In(loc)
+	}
+	t := now
+	if m[1] != "now" {
+		days := map[string]int{"today": 0, "yesterday": -1, "tomorrow": 1}[m[1]]
+		t = time.Date(now.Year(), now.Month(), now.Day()+days, 0, 0, 0, 0, now.Location())
+	}
+	if m[4] != "" {
+		if m[1] == "now" {
+			return time.Time{}, fmt.Errorf("invalid time %q: a time of day only follows today, yesterday or tomorrow", m[0])
+		}
+		hour, _ := strconv.Atoi(m[4])
+		minute, _ := strconv.Atoi(m[5])
+		second := 0
+		if m[6] != "" {
+			second, _ = strconv.Atoi(m[6])
+		}
+		if hour > 23 || minute > 59 || second > 59 {
+			return time.Time{}, fmt.Errorf("invalid time %q: no such time of day", m[0])
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day(), hour, minute, second, 0, t.Location())
+	}
+	if m[3] != "" {
+		d, err := parseDuration(m[3], true)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q: %w", m[0], err)
+		}
+		if m[2] == "-" {
+			d = -d
+		}
+		t = t.Add(d)
+	}
+	return t, nil
+}
+
+// formatTime writes t with the first layout of the flag, in its zone, or as RFC 3339 with the fraction of a second kept
+func formatTime(f FlagSpec, t time.Time) string {
+	if loc, err := locationOf(f); err == nil && loc != nil {
+		t = t.In(loc)
+	}
+	if len(f.Layouts) == 0 {
+		return t.Format(time.RFC3339Nano)
+	}
+	return t.Format(f.Layouts[0])
+}
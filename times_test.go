@@ -0,0 +1,176 @@
+package clix
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Retention struct {
+	Keep  time.Duration `cli:"keep" cli-default:"7d" cli-max:"52w"`
+	Since time.Time     `cli:"since"`
+	Until *time.Time    `cli:"until"`
+	Day   time.Time     `cli:"day" cli-layout:"DateOnly|2006-01-02 15:04" cli-tz:"Europe/Stockholm"`
+}
+
+// clock is the time WithClock returns in the tests, a Sunday afternoon
+var clock = time.Date(2024, 3, 10, 14, 30, 0, 0, time.UTC)
+
+func fixedClock() time.Time { return clock }
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90s":   90 * time.Second,
+		"1h30m": 90 * time.Minute,
+		"7d":    7 * 24 * time.Hour,
+		"2w":    14 * 24 * time.Hour,
+		"1d12h": 36 * time.Hour,
+		"1.5d":  36 * time.Hour,
+		"-1d":   -24 * time.Hour,
+	}
+	for in, want := range tests {
+		d, err := parseDuration(in, true)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, d, in)
+	}
+
+	_, err := parseDuration("7d", false)
+	assert.EqualError(t, err, `time: unknown unit "d" in duration "7d"`)
+	_, err = parseDuration("7x", true)
+	assert.EqualError(t, err, `invalid duration "7x", expected e.g. 90s, 1h30m, 7d or 2w`)
+}
+
+func TestParseRelativeTime(t *testing.T) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	require.NoError(t, err)
+
+	tests := []struct {
+		in   string
+		loc  *time.Location
+		want time.Time
+	}{
+		{"now", nil, clock},
+		{"now-1h", nil, clock.Add(-time.Hour)},
+		{"now + 2d", nil, clock.Add(48 * time.Hour)},
+		{"today", nil, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"today-1w", nil, time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"yesterday 08:00", nil, time.Date(2024, 3, 9, 8, 0, 0, 0, time.UTC)},
+		{"tomorrow 8:00:30", nil, time.Date(2024, 3, 11, 8, 0, 30, 0, time.UTC)},
+		{"today", stockholm, time.Date(2024, 3, 10, 0, 0, 0, 0, stockholm)},
+		{"2024-01-02T03:04:05+01:00", nil, time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseTime(tt.in, []string{time.RFC3339}, tt.loc, true, fixedClock)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want.UTC(), got.UTC(), tt.in)
+	}
+
+	errs := map[string]string{
+		"now 08:00":       `invalid time "now 08:00": a time of day only follows today, yesterday or tomorrow`,
+		"yesterday 25:00": `invalid time "yesterday 25:00": no such time of day`,
+		"now-1x":          `invalid time "now-1x": invalid duration "1x", expected e.g. 90s, 1h30m, 7d or 2w`,
+		"soon":            `invalid time "soon", expected "2006-01-02T15:04:05Z07:00" or a relative time such as "now-1h", "today" or "yesterday 08:00"`,
+	}
+	for in, msg := range errs {
+		_, err := parseTime(in, []string{time.RFC3339}, nil, true, fixedClock)
+		assert.EqualError(t, err, msg, in)
+	}
+
+	// relative times are opt-in
+	_, err = parseTime("today", []string{time.RFC3339}, nil, false, fixedClock)
+	assert.EqualError(t, err, `invalid time "today", expected "2006-01-02T15:04:05Z07:00"`)
+}
+
+func TestParseExtendedTime(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+keep: 2w
+since: now-1h
+until: tomorrow
+day: "2024-03-01 12:00"
+`))
+	require.NoError(t, err)
+
+	cfg, err := TryParse[Retention](r, WithExtendedTime(), WithClock(fixedClock))
+	require.NoError(t, err)
+	assert.Equal(t, 14*24*time.Hour, cfg.Keep)
+	assert.Equal(t, clock.Add(-time.Hour), cfg.Since)
+	require.NotNil(t, cfg.Until)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), *cfg.Until)
+	assert.Equal(t, time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), cfg.Day.UTC())
+
+	// without the option, only the layouts of the field are understood
+	r, err = NewFileReader([]byte(`day: "2024-03-01"`))
+	require.NoError(t, err)
+	cfg, err = TryParse[Retention](r)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC), cfg.Day.UTC())
+
+	ctx := setMockContext{cliContextMock: newMockContext(), set: map[string]bool{"since": true}}
+	ctx.stringMap["since"] = "now-1h"
+	_, err = TryParse[Retention](ctx)
+	assert.NoError(t, err, "the text is not read without the option")
+}
+
+func TestParseExtendedTimeErrors(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+keep: 53w
+since: later
+day: 01/03/2024
+`))
+	require.NoError(t, err)
+
+	_, err = TryParse[Retention](r, WithExtendedTime(), WithClock(fixedClock))
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		`--since: invalid time "later", expected "2006-01-02T15:04:05Z07:00" or a relative time such as "now-1h", "today" or "yesterday 08:00"`,
+		`--day: invalid time "01/03/2024", expected "2006-01-02" or "2006-01-02 15:04" or a relative time such as "now-1h", "today" or "yesterday 08:00"`,
+		"--keep: 8904h0m0s is greater than 52w",
+	}, messages)
+}
+
+func TestCompileTimeTagErrors(t *testing.T) {
+	type Config struct {
+		Name string        `cli:"name" cli-layout:"DateOnly"`
+		At   time.Time     `cli:"at" cli-tz:"Mars/Olympus"`
+		Keep time.Duration `cli:"keep" cli-default:"forever"`
+	}
+	_, err := Compile[Config]()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"Name: cli-layout and cli-tz on a field that is not a time.Time",
+		`At: invalid cli-tz "Mars/Olympus": unknown time zone Mars/Olympus`,
+		`--keep: invalid cli-default "forever": invalid duration "forever", expected e.g. 90s, 1h30m, 7d or 2w`,
+	}, messages)
+}
+
+func TestTimesToArgsAndDocs(t *testing.T) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	require.NoError(t, err)
+	cfg := Retention{
+		Keep:  7 * 24 * time.Hour,
+		Since: clock,
+		Day:   time.Date(2024, 3, 1, 0, 0, 0, 0, stockholm),
+	}
+	args, err := ToArgs(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--since=2024-03-10T14:30:00Z", "--day=2024-03-01"}, args)
+
+	spec := Describe[Retention]()
+	assert.Equal(t, "Format: `2006-01-02`, `2006-01-02 15:04`. Time zone: Europe/Stockholm.", flagDescription(spec.Flags[3]))
+	schema, err := JSONSchema[Retention]()
+	require.NoError(t, err)
+	assert.Empty(t, schema.Properties["day"].Format)
+}
@@ -0,0 +1,185 @@
+package clix
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// variant is a registered implementation of a union interface
+type variant struct {
+	name string
+	typ  reflect.Type
+}
+
+var (
+	variantsMu sync.RWMutex
+	variants   = map[reflect.Type][]variant{}
+)
+
+// RegisterVariant registers V as the variant name of the interface I, for fields tagged `cli-union`.
+// V is a struct, stored in the interface as V when V implements I and as *V otherwise.
+// The flags of a variant are those of V prefixed with the `cli-prefix` of the field, if any, and the variant name.
+// Variants are registered from init functions, before the structs using I are parsed, and it panics
+// if I is not an interface, if V or *V does not implement it, or if the name is already taken.
+// example
+//
+//	type Storage interface{ Open() (Store, error) }
+//
+//	func init() {
+//		clix.RegisterVariant[Storage, S3]("s3")   // --s3-bucket ...
+//		clix.RegisterVariant[Storage, Disk]("fs") // --fs-root ...
+//	}
+//
+//	type Config struct {
+//		Storage Storage `cli-union:"storage" cli-usage:"where to store blobs"` // --storage s3
+//	}
+func RegisterVariant[I any, V any](name string) {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	typ := reflect.TypeOf((*V)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("clix: RegisterVariant: %s is not an interface", iface))
+	}
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("clix: RegisterVariant: %s is not a struct", typ))
+	}
+	if !typ.Implements(iface) {
+		typ = reflect.PointerTo(typ)
+		if !typ.Implements(iface) {
+			panic(fmt.Sprintf("clix: RegisterVariant: %s does not implement %s", typ.Elem(), iface))
+		}
+	}
+
+	variantsMu.Lock()
+	defer variantsMu.Unlock()
+	for _, v := range variants[iface] {
+		if v.name == name {
+			panic(fmt.Sprintf("clix: RegisterVariant: variant %q of %s registered twice", name, iface))
+		}
+	}
+	variants[iface] = append(variants[iface], variant{name: name, typ: typ})
+}
+
+func variantsOf(iface reflect.Type) []variant {
+	variantsMu.RLock()
+	defer variantsMu.RUnlock()
+	return append([]variant{}, variants[iface]...)
+}
+
+// assignUnion sets the interface field of u to the variant named by the discriminator flag, or its `cli-default`,
+// assigned from the flags of the variant.
+// Flags given for the other variants are reported, when the reader can tell which flags were given.
+func assignUnion(u UnionSpec, val reflect.Value, prefix string, c ContextReader, o *options) error {
+	errs := &ParseError{}
+	name := c.String(prefix + u.Flag)
+	if name == "" {
+		name = u.Default
+	}
+	if isSet, ok := c.(IsSetReader); ok {
+		for _, v := range u.Variants {
+			if v.Name == name {
+				continue
+			}
+			for _, flag := range setFlags(planFor(indirect(v.GoType), o), prefix+v.Prefix, c, isSet) {
+				errs.add(flag, u.Field, fmt.Errorf("only used when --%s is %s", prefix+u.Flag, v.Name))
+			}
+		}
+	}
+
+	field := fieldByIndexAlloc(val, u.Index)
+	if name == "" {
+		field.SetZero()
+		return errs.orNil()
+	}
+	v, ok := u.Variant(name)
+	if !ok {
+		field.SetZero()
+		errs.add(prefix+u.Flag, u.Field, fmt.Errorf("%q is not one of %s", name, strings.Join(u.Names(), ", ")))
+		return errs.orNil()
+	}
+
+	elem := reflect.New(indirect(v.GoType))
+	if err := planFor(elem.Elem().Type(), o).assign(elem.Elem(), prefix+v.Prefix, c, o); err != nil {
+		errs.add("", "", err)
+	}
+	if v.GoType.Kind() != reflect.Ptr {
+		elem = elem.Elem()
+	}
+	field.Set(elem)
+	return errs.orNil()
+}
+
+// setFlags returns the flags of p, read with prefix, that the reader reports as set. Besides the flags of p and of
+// its sections, those are the flags of its lists, maps and unions, and of their elements as far as the reader lists them.
+func setFlags(p *Plan, prefix string, c ContextReader, isSet IsSetReader) []string {
+	var set []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] && isSet.IsSet(name) {
+			seen[name] = true
+			set = append(set, name)
+		}
+	}
+	p.spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		for _, n := range f.Names() {
+			add(prefix + n)
+		}
+	})
+
+	// the flags of list elements, map instances and variants start with their prefix
+	var prefixes []string
+	p.spec.WalkLists(func(_ SectionSpec, l ListSpec) {
+		add(prefix + l.FlagName())
+		prefixes = append(prefixes, normalizeKey(prefix+l.Prefix))
+	})
+	p.spec.WalkMaps(func(_ SectionSpec, m MapSpec) {
+		if m.Keys != "" {
+			add(prefix + m.Keys)
+		}
+		prefixes = append(prefixes, normalizeKey(prefix+m.Prefix))
+	})
+	p.spec.WalkUnions(func(_ SectionSpec, u UnionSpec) {
+		add(prefix + u.Flag)
+		for _, v := range u.Variants {
+			if v.Prefix != "" {
+				prefixes = append(prefixes, normalizeKey(prefix+v.Prefix))
+			}
+		}
+	})
+	if r, ok := c.(FlagNamesReader); ok && len(prefixes) > 0 {
+		for _, name := range r.FlagNames() {
+			for _, pre := range prefixes {
+				if strings.HasPrefix(normalizeKey(name), pre) {
+					add(name)
+					break
+				}
+			}
+		}
+	}
+	return set
+}
+
+// unionValue returns the variant held by the interface field and the struct it stores, if any
+func unionValue(u UnionSpec, field reflect.Value) (VariantSpec, reflect.Value, bool) {
+	if field.IsNil() {
+		return VariantSpec{}, reflect.Value{}, false
+	}
+	elem := field.Elem()
+	for _, v := range u.Variants {
+		if v.GoType != elem.Type() {
+			continue
+		}
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return v, reflect.Zero(elem.Type().Elem()), true
+			}
+			return v, elem.Elem(), true
+		}
+		// values in an interface are not addressable, Validate methods with a pointer receiver need a copy
+		cp := reflect.New(elem.Type()).Elem()
+		cp.Set(elem)
+		return v, cp, true
+	}
+	return VariantSpec{}, reflect.Value{}, false
+}
@@ -0,0 +1,199 @@
+package clix
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Storage interface {
+	Kind() string
+}
+
+type S3Storage struct {
+	Bucket string `cli:"bucket" cli-required:"true"`
+	Region string `cli:"region" cli-default:"eu-north-1"`
+}
+
+func (s S3Storage) Kind() string { return "s3" }
+
+type FSStorage struct {
+	Root string `cli:"root"`
+}
+
+func (s *FSStorage) Kind() string { return "fs" }
+
+func (s *FSStorage) Validate() error {
+	if s.Root == "/" {
+		return errors.New("refusing to store in /")
+	}
+	return nil
+}
+
+type Queue interface {
+	Queue() string
+}
+
+type KafkaQueue struct {
+	Brokers string `cli:"brokers"`
+	TLS     struct {
+		Cert string `cli:"cert"`
+	} `cli-prefix:"tls-"`
+	Mirrors []Upstream `cli-prefix:"mirror-"`
+}
+
+func (KafkaQueue) Queue() string { return "kafka" }
+
+type MemQueue struct {
+	Size int `cli:"size"`
+}
+
+func (MemQueue) Queue() string { return "mem" }
+
+func init() {
+	RegisterVariant[Storage, S3Storage]("s3")
+	RegisterVariant[Storage, FSStorage]("fs")
+	RegisterVariant[Queue, KafkaQueue]("kafka")
+	RegisterVariant[Queue, MemQueue]("mem")
+}
+
+type Blobs struct {
+	Storage Storage `cli-union:"storage" cli-usage:"where blobs are stored" cli-required:"true"`
+	Backup  Storage `cli-union:"backup" cli-prefix:"backup-" cli-default:"fs"`
+}
+
+func TestRegisterVariantPanics(t *testing.T) {
+	assert.PanicsWithValue(t, "clix: RegisterVariant: clix.S3Storage is not an interface", func() {
+		RegisterVariant[S3Storage, S3Storage]("x")
+	})
+	assert.PanicsWithValue(t, "clix: RegisterVariant: clix.Upstream does not implement clix.Storage", func() {
+		RegisterVariant[Storage, Upstream]("x")
+	})
+	assert.PanicsWithValue(t, `clix: RegisterVariant: variant "s3" of clix.Storage registered twice`, func() {
+		RegisterVariant[Storage, S3Storage]("s3")
+	})
+}
+
+func TestDescribeUnion(t *testing.T) {
+	spec := Describe[Blobs]()
+	require.Len(t, spec.Unions, 2)
+	u := spec.Unions[0]
+	assert.Equal(t, "storage", u.Flag)
+	assert.Equal(t, []string{"s3", "fs"}, u.Names())
+	assert.Equal(t, "s3-", u.Variants[0].Prefix)
+	assert.Equal(t, "backup-fs-", spec.Unions[1].Variants[1].Prefix)
+
+	var names []string
+	spec.Unions[1].Variants[0].Spec().Walk(func(_ SectionSpec, f FlagSpec) { names = append(names, f.Name) })
+	assert.Equal(t, []string{"backup-s3-bucket", "backup-s3-region"}, names)
+
+	_, err := Compile[Blobs]()
+	assert.NoError(t, err)
+}
+
+func TestParseUnion(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+storage: s3
+s3:
+  bucket: blobs
+  region: us-east-1
+backup-fs-root: /backup
+`))
+	require.NoError(t, err)
+
+	cfg, err := TryParse[Blobs](r)
+	require.NoError(t, err)
+	assert.Equal(t, S3Storage{Bucket: "blobs", Region: "us-east-1"}, cfg.Storage)
+	// the default variant, stored as a pointer as only *FSStorage implements Storage
+	assert.Equal(t, &FSStorage{Root: "/backup"}, cfg.Backup)
+}
+
+func TestParseUnionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{"other variant", "storage: s3\ns3-bucket: b\nfs-root: /data\n", "--fs-root: only used when --storage is fs"},
+		{"unknown", "storage: ftp\n", `--storage: "ftp" is not one of s3, fs`},
+		{"missing", "fs-root: /data\n", "--fs-root: only used when --storage is fs; --storage: required flag is not set"},
+		{"required in variant", "storage: s3\n", "--s3-bucket: required flag is not set"},
+		{"validate", "storage: fs\nfs-root: /\n", "Storage: refusing to store in /"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewFileReader([]byte(tt.yaml))
+			require.NoError(t, err)
+			_, err = TryParse[Blobs](r)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseUnionOtherVariantSections(t *testing.T) {
+	type Config struct {
+		Queue Queue `cli-union:"queue"`
+	}
+	r, err := NewFileReader([]byte(`
+queue: mem
+kafka:
+  tls:
+    cert: /etc/kafka.pem
+kafka-mirror-1-host: b.example.com
+`))
+	require.NoError(t, err)
+
+	_, err = TryParse[Config](r)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"--kafka-tls-cert: only used when --queue is kafka",
+		"--kafka-mirror-1-host: only used when --queue is kafka",
+	}, messages)
+}
+
+func TestCompileUnionErrors(t *testing.T) {
+	type Unregistered interface{ Unregistered() }
+	type Config struct {
+		A Unregistered `cli-union:"a"`
+		B Storage      `cli-union:"b" cli-default:"ftp"`
+		C string       `cli-union:"c"`
+	}
+	_, err := Compile[Config]()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		"C: cli-union on a field that is not an interface",
+		"--a: no variants registered for clix.Unregistered",
+		`--b: invalid cli-default "ftp": not one of s3, fs`,
+	}, messages)
+}
+
+func TestDiffUnion(t *testing.T) {
+	a := Blobs{Storage: S3Storage{Bucket: "a"}, Backup: &FSStorage{Root: "/x"}}
+	b := Blobs{Storage: &FSStorage{Root: "/data"}, Backup: &FSStorage{Root: "/y"}}
+	assert.Equal(t, []Change{
+		{Flag: "storage", Field: "Storage", Old: "s3", New: "fs"},
+		{Flag: "s3-bucket", Field: "Storage.Bucket", Old: "a", New: ""},
+		{Flag: "fs-root", Field: "Storage.Root", Old: "", New: "/data"},
+		{Flag: "backup-fs-root", Field: "Backup.Root", Old: "/x", New: "/y"},
+	}, Diff(a, b))
+}
+
+func TestDocsUnion(t *testing.T) {
+	md := DocsMarkdown[Blobs](DocsOptions{})
+	assert.Contains(t, md, "# Storage\n\nwhere blobs are stored\n\nUnion: `--storage` selects one of `s3`, `fs`, **required**\n\n## s3\n\nRead with `--storage s3`\n\n")
+	assert.Contains(t, md, "| `--s3-bucket` |")
+	assert.Contains(t, md, "Union: `--backup` selects one of `s3`, `fs`, default `fs`")
+	assert.Contains(t, md, "| `--backup-fs-root` |")
+}
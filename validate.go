@@ -0,0 +1,392 @@
+package clix
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator can be implemented by a config struct, or any nested struct in it,
+// to add checks that can not be expressed with tags. It is called by TryParse after the tag rules passed.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError is a problem with the value of a single flag or positional argument
+type FieldError struct {
+	Flag  string // full flag name, empty for arguments and errors returned by a Validator
+	Arg   string // name of the positional argument, e.g. "src", empty for flags
+	Field string // Go field path
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Arg != "" {
+		return "<" + e.Arg + ">: " + e.Err.Error()
+	}
+	if e.Flag == "" {
+		if e.Field == "" {
+			return e.Err.Error()
+		}
+		return e.Field + ": " + e.Err.Error()
+	}
+	return "--" + e.Flag + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError holds every FieldError found while parsing a config
+type ParseError struct {
+	Errors []*FieldError
+}
+
+func (e *ParseError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ParseError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// add appends a field error, flattening nested ParseErrors
+func (e *ParseError) add(flag, field string, err error) {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		e.Errors = append(e.Errors, pe.Errors...)
+		return
+	}
+	e.Errors = append(e.Errors, &FieldError{Flag: flag, Field: field, Err: err})
+}
+
+func (e *ParseError) orNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// TryParse is Parse with validation.
+// After the struct is populated the `cli-required`, `cli-oneof`, `cli-min` and `cli-max` tags are checked,
+// and Validate is called on every struct implementing Validator, innermost first.
+// All problems are returned together in a *ParseError.
+//
+// A required flag is missing when its field holds the zero value and the reader does not report any of its names as set through IsSetReader.
+// A flag given under several of its names, with different values, is reported as well, and so are the errors
+// Compile reports for A, such as a flag name promoted by two embedded structs.
+func TryParse[A any](c ContextReader, opts ...Option) (A, error) {
+	o := newOptions(opts)
+	cfg, err := parse[A](c, o)
+	errs := &ParseError{}
+	if err := planFor(reflect.TypeOf((*A)(nil)).Elem(), o).errors(o); err != nil {
+		errs.add("", "", err)
+	}
+	if err != nil {
+		errs.add("", "", err)
+	}
+	if err := validateValue(reflect.ValueOf(&cfg).Elem(), c, o); err != nil {
+		errs.add("", "", err)
+	}
+	return cfg, errs.orNil()
+}
+
+// TryParseCommand is the v3 counterpart of TryParse, a shorthand for `clix.TryParse[Config](clix.V3(cmd))`
+func TryParseCommand[A any](cmd CommandReaderV3, opts ...Option) (A, error) {
+	return TryParse[A](V3(cmd), opts...)
+}
+
+func validateValue(val reflect.Value, c ContextReader, o *options) error {
+	errs := &ParseError{}
+	validateStruct(val, "", "", c, o, errs)
+	return errs.orNil()
+}
+
+// validateStruct validates val, read with the flag prefix, whose field paths are reported under path.
+// The elements of slices of structs are validated the same way, with the prefix and path of the element.
+func validateStruct(val reflect.Value, prefix, path string, c ContextReader, o *options, errs *ParseError) {
+	p := planFor(val.Type(), o)
+	p.spec.Walk(func(_ SectionSpec, f FlagSpec) {
+		if err := validateFlag(f, prefix, fieldByIndexOrZero(val, f.Index, f.GoType), c); err != nil {
+			errs.add(prefix+f.Name, joinPath(path, f.Field), err)
+		}
+	})
+
+	for _, l := range p.lists {
+		list := fieldByIndexOrZero(val, l.Index, l.GoType)
+		indexes := listIndexes(c, prefix, l)
+		for i := 0; i < list.Len(); i++ {
+			elem := list.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					continue
+				}
+				elem = elem.Elem()
+			}
+			// elements read from a JSON or YAML array have no flags of their own
+			elemPrefix := prefix + l.FlagName() + "[" + strconv.Itoa(i) + "]."
+			if len(indexes) == list.Len() {
+				elemPrefix = prefix + l.ElemPrefix(indexes[i])
+			}
+			validateStruct(elem, elemPrefix, fmt.Sprintf("%s[%d]", joinPath(path, l.Field), i), c, o, errs)
+		}
+	}
+
+	for _, m := range p.maps {
+		instances := fieldByIndexOrZero(val, m.Index, m.GoType)
+		for _, key := range sortedKeys(instances) {
+			elem := instances.MapIndex(key)
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					continue
+				}
+				elem = elem.Elem()
+			}
+			// map values are not addressable, Validate methods with a pointer receiver need a copy
+			cp := reflect.New(elem.Type()).Elem()
+			cp.Set(elem)
+			validateStruct(cp, prefix+m.ElemPrefix(key.String()), fmt.Sprintf("%s[%s]", joinPath(path, m.Field), key.String()), c, o, errs)
+		}
+	}
+
+	if len(p.args) > 0 {
+		args := readArgs(c)
+		for _, a := range p.args {
+			texts, set := a.values(args, p.rest)
+			if set {
+				if _, err := a.parse(texts, o); err != nil {
+					// the conversion error was reported by assignArg
+					continue
+				}
+			}
+			if err := checkRules(a.flagSpec(), fieldByIndexOrZero(val, a.Index, a.GoType), set, "argument"); err != nil {
+				errs.Errors = append(errs.Errors, &FieldError{Arg: a.Name, Field: joinPath(path, a.Field), Err: err})
+			}
+		}
+	}
+
+	for _, u := range p.unions {
+		field := fieldByIndexOrZero(val, u.Index, u.GoType)
+		v, elem, ok := unionValue(u, field)
+		if !ok {
+			// an unknown variant was reported by assignUnion
+			r, canTell := c.(IsSetReader)
+			if u.Required && field.IsNil() && !(canTell && r.IsSet(prefix+u.Flag)) {
+				errs.add(prefix+u.Flag, joinPath(path, u.Field), errors.New("required flag is not set"))
+			}
+			continue
+		}
+		validateStruct(elem, prefix+v.Prefix, joinPath(path, u.Field), c, o, errs)
+	}
+
+	callValidators(val, path, errs)
+}
+
+// sortedKeys returns the keys of a map with string keys, sorted
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
+
+// callValidators calls Validate on nested structs before their parents
+func callValidators(val reflect.Value, path string, errs *ParseError) {
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		fieldType := val.Type().Field(i)
+		if _, ok := sectionType(fieldType.Type); !ok || !fieldType.IsExported() || fieldType.Tag.Get("cli") != "" || fieldType.Tag.Get("cli-cmd") != "" {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		callValidators(field, joinPath(path, fieldType.Name), errs)
+	}
+
+	// a Validate promoted from an embedded struct was called on the embedded struct above
+	if promotedValidate(val.Type()) {
+		return
+	}
+
+	var v Validator
+	if val.CanAddr() {
+		v, _ = val.Addr().Interface().(Validator)
+	} else {
+		v, _ = val.Interface().(Validator)
+	}
+	if v == nil {
+		return
+	}
+	if err := v.Validate(); err != nil {
+		errs.add("", path, err)
+	}
+}
+
+// promotedValidate reports whether the Validate method of t, or *t, is promoted from an embedded field rather than declared.
+// Promoted methods are compiler generated wrappers, and their source position is the only place reflect shows that.
+func promotedValidate(t reflect.Type) bool {
+	m, ok := t.MethodByName("Validate")
+	if !ok {
+		if m, ok = reflect.PointerTo(t).MethodByName("Validate"); !ok {
+			return false
+		}
+	}
+	pc := m.Func.Pointer()
+	file, _ := runtime.FuncForPC(pc).FileLine(pc)
+	return file == "<autogenerated>"
+}
+
+func validateFlag(f FlagSpec, prefix string, field reflect.Value, c ContextReader) error {
+	set := false
+	if r, ok := c.(IsSetReader); ok {
+		for _, name := range f.Names() {
+			set = set || r.IsSet(prefix+name)
+		}
+	}
+	return checkRules(f, field, set, "flag")
+}
+
+// checkRules applies the required, oneof and bound rules of f to the field, set tells whether a value was given.
+// what names the kind of input in the required error, "flag" or "argument".
+func checkRules(f FlagSpec, field reflect.Value, set bool, what string) error {
+	if field.IsZero() && !set {
+		if f.Required {
+			return fmt.Errorf("required %s is not set", what)
+		}
+		return nil
+	}
+
+	if len(f.Enum) > 0 {
+		for _, v := range textValues(field) {
+			if !contains(f.Enum, v) {
+				return fmt.Errorf("%q is not one of %s", v, strings.Join(f.Enum, ", "))
+			}
+		}
+	}
+	if f.Min != "" {
+		if err := checkBound(field, f.Min, -1); err != nil {
+			return err
+		}
+	}
+	if f.Max != "" {
+		if err := checkBound(field, f.Max, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBound fails when the field is below (sign -1) or above (sign 1) the bound.
+// Numbers are compared by value, durations as durations, and strings, slices and maps by length.
+// Text types that are numbers underneath, ByteSize and Percent, take bounds written as values, "10MiB".
+func checkBound(field reflect.Value, bound string, sign int) error {
+	var cmp int
+	var err error
+
+	switch {
+	case field.Type() == reflect.TypeOf(time.Duration(0)):
+		var b time.Duration
+		if b, err = parseDuration(bound, true); err == nil {
+			cmp = compare(time.Duration(field.Int()), b)
+		}
+	case isTextType(field.Type()) && isNumber(field.Type()):
+		var b reflect.Value
+		if b, err = parseText(field.Type(), bound); err == nil {
+			switch {
+			case field.CanInt():
+				cmp = compare(field.Int(), b.Int())
+			case field.CanUint():
+				cmp = compare(field.Uint(), b.Uint())
+			default:
+				cmp = compare(field.Float(), b.Float())
+			}
+		}
+	case field.CanInt():
+		var b int64
+		if b, err = strconv.ParseInt(bound, 10, 64); err == nil {
+			cmp = compare(field.Int(), b)
+		}
+	case field.CanUint():
+		var b uint64
+		if b, err = strconv.ParseUint(bound, 10, 64); err == nil {
+			cmp = compare(field.Uint(), b)
+		}
+	case field.CanFloat():
+		var b float64
+		if b, err = strconv.ParseFloat(bound, 64); err == nil {
+			cmp = compare(field.Float(), b)
+		}
+	case field.Kind() == reflect.String, field.Kind() == reflect.Slice, field.Kind() == reflect.Map:
+		var b int
+		if b, err = strconv.Atoi(bound); err == nil {
+			cmp = compare(field.Len(), b)
+			if cmp == sign {
+				if sign < 0 {
+					return fmt.Errorf("length %d is less than %d", field.Len(), b)
+				}
+				return fmt.Errorf("length %d is greater than %d", field.Len(), b)
+			}
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid bound %q: %w", bound, err)
+	}
+	if cmp == sign {
+		if sign < 0 {
+			return fmt.Errorf("%v is less than %s", field.Interface(), bound)
+		}
+		return fmt.Errorf("%v is greater than %s", field.Interface(), bound)
+	}
+	return nil
+}
+
+func compare[T int | int64 | uint64 | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// textValues returns the text form of the value, one per element for slices
+func textValues(field reflect.Value) []string {
+	if field.Kind() == reflect.Slice {
+		values := make([]string, field.Len())
+		for i := range values {
+			values[i] = fmt.Sprint(field.Index(i).Interface())
+		}
+		return values
+	}
+	return []string{fmt.Sprint(field.Interface())}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
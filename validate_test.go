@@ -0,0 +1,131 @@
+package clix
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ValidatedConfig struct {
+	Name    string        `cli:"name" cli-required:"true"`
+	Mode    string        `cli:"mode" cli-oneof:"dev,prod"`
+	Port    int           `cli:"port" cli-min:"1" cli-max:"65535"`
+	Ratio   float64       `cli:"ratio" cli-max:"1"`
+	Timeout time.Duration `cli:"timeout" cli-min:"1s"`
+	Tags    []string      `cli:"tags" cli-oneof:"a,b" cli-max:"2"`
+	Server  ServerConfig  `cli-prefix:"server-"`
+}
+
+type ServerConfig struct {
+	Host string `cli:"host" cli-min:"3"`
+}
+
+func (s *ServerConfig) Validate() error {
+	if s.Host == "localhost" {
+		return errors.New("localhost is not allowed")
+	}
+	return nil
+}
+
+// setMockContext is a cliContextMock that reports which flags were set
+type setMockContext struct {
+	*cliContextMock
+	set map[string]bool
+}
+
+func (m setMockContext) IsSet(name string) bool { return m.set[name] }
+
+func TestTryParseValid(t *testing.T) {
+	ctx := newMockContext()
+	ctx.stringMap["name"] = "app"
+	ctx.stringMap["mode"] = "prod"
+	ctx.intMap["port"] = 8080
+	ctx.durationMap["timeout"] = time.Minute
+	ctx.stringSliceMap["tags"] = []string{"a", "b"}
+	ctx.stringMap["server-host"] = "example.com"
+
+	config, err := TryParse[ValidatedConfig](ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "app", config.Name)
+	assert.Equal(t, "example.com", config.Server.Host)
+}
+
+func TestTryParseInvalid(t *testing.T) {
+	ctx := newMockContext()
+	ctx.stringMap["mode"] = "test"
+	ctx.intMap["port"] = 70000
+	ctx.float64Map["ratio"] = 1.5
+	ctx.durationMap["timeout"] = time.Millisecond
+	ctx.stringSliceMap["tags"] = []string{"a", "b", "c"}
+	ctx.stringMap["server-host"] = "localhost"
+
+	_, err := TryParse[ValidatedConfig](ctx)
+	require.Error(t, err)
+
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+
+	messages := map[string]string{}
+	for _, fe := range pe.Errors {
+		messages[fe.Field] = fe.Error()
+	}
+	assert.Equal(t, map[string]string{
+		"Name":    "--name: required flag is not set",
+		"Mode":    `--mode: "test" is not one of dev, prod`,
+		"Port":    "--port: 70000 is greater than 65535",
+		"Ratio":   "--ratio: 1.5 is greater than 1",
+		"Timeout": "--timeout: 1ms is less than 1s",
+		"Tags":    `--tags: "c" is not one of a, b`,
+		"Server":  "Server: localhost is not allowed",
+	}, messages)
+}
+
+func TestTryParseBoundsOnLength(t *testing.T) {
+	ctx := newMockContext()
+	ctx.stringMap["name"] = "app"
+	ctx.stringSliceMap["tags"] = []string{"a", "a", "b"}
+	ctx.stringMap["server-host"] = "ab"
+
+	_, err := TryParse[ValidatedConfig](ctx)
+	assert.EqualError(t, err, "--tags: length 3 is greater than 2; --server-host: length 2 is less than 3")
+}
+
+func TestTryParseRequiredUsesIsSet(t *testing.T) {
+	type Config struct {
+		Count int `cli:"count" cli-required:"true" cli-min:"1"`
+	}
+
+	// An explicit zero is given, so required passes but the bound is checked
+	ctx := setMockContext{cliContextMock: newMockContext(), set: map[string]bool{"count": true}}
+	_, err := TryParse[Config](ctx)
+	assert.EqualError(t, err, "--count: 0 is less than 1")
+
+	ctx.set["count"] = false
+	_, err = TryParse[Config](ctx)
+	assert.EqualError(t, err, "--count: required flag is not set")
+}
+
+type rootValidated struct {
+	Level int `cli:"level"`
+}
+
+func (r rootValidated) Validate() error {
+	if r.Level > 3 {
+		return errors.New("level too high")
+	}
+	return nil
+}
+
+func TestTryParseRootValidator(t *testing.T) {
+	ctx := newMockContext()
+	ctx.intMap["level"] = 4
+
+	_, err := TryParse[rootValidated](ctx)
+	assert.EqualError(t, err, "level too high")
+
+	_, err = TryParseCommand[rootValidated](&mockCommandReaderV3{intMap: map[string]int{"level": 2}})
+	assert.NoError(t, err)
+}
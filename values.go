@@ -0,0 +1,325 @@
+package clix
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Field types with a text form, read from string flags and written back by their String method, see isTextType.
+var (
+	_ encoding.TextUnmarshaler = (*ByteSize)(nil)
+	_ encoding.TextUnmarshaler = (*Percent)(nil)
+	_ encoding.TextUnmarshaler = (*Rate)(nil)
+)
+
+// ByteSize is a number of bytes written with a decimal (KB, MB, ...) or binary (KiB, MiB, ...) unit, "10MiB" or "1.5GB".
+// A number without unit is a number of bytes.
+type ByteSize int64
+
+// Units of ByteSize, binary before decimal so that String prefers them on ties
+var byteUnits = []struct {
+	name string
+	size int64
+}{
+	{"EiB", 1 << 60}, {"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+	{"EB", 1e18}, {"PB", 1e15}, {"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3}, {"kB", 1e3},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size such as "512", "10MiB" or "1.5GB". Fractions are allowed as long as they are whole bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	num, unit := splitNumber(s)
+	r, ok := new(big.Rat).SetString(num)
+	if !ok || num == "" || strings.ContainsAny(num, "+-eE/") {
+		return 0, fmt.Errorf("invalid byte size %q: expected a number and a unit, e.g. 10MiB", s)
+	}
+	size := int64(1)
+	if unit != "" {
+		size = 0
+		for _, u := range byteUnits {
+			if u.name == unit {
+				size = u.size
+			}
+		}
+		if size == 0 {
+			return 0, fmt.Errorf("invalid byte size %q: unknown unit %q, expected B, KB, MB, GB, TB, PB, EB or KiB, MiB, GiB, TiB, PiB, EiB", s, unit)
+		}
+	}
+	r.Mul(r, new(big.Rat).SetInt64(size))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("invalid byte size %q: not a whole number of bytes", s)
+	}
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("invalid byte size %q: out of range", s)
+	}
+	return ByteSize(r.Num().Int64()), nil
+}
+
+// String returns the shortest form that parses back to the same size, "10MiB" rather than "10485760B".
+func (b ByteSize) String() string {
+	best := strconv.FormatInt(int64(b), 10) + "B"
+	if b <= 0 {
+		return best
+	}
+	for _, u := range byteUnits {
+		if int64(b) < u.size {
+			continue
+		}
+		s := strconv.FormatFloat(float64(b)/float64(u.size), 'f', -1, 64) + u.name
+		if len(s) < len(best) {
+			if back, err := ParseByteSize(s); err == nil && back == b {
+				best = s
+			}
+		}
+	}
+	return best
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// Percent is a percentage written with a percent sign, "85%" is Percent(85).
+type Percent float64
+
+// ParsePercent parses a percentage such as "85%" or "12.5%", the percent sign is required.
+func ParsePercent(s string) (Percent, error) {
+	num, ok := strings.CutSuffix(s, "%")
+	if !ok {
+		return 0, fmt.Errorf("invalid percentage %q: expected a number followed by %%, e.g. 85%%", s)
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || strings.TrimSpace(num) != num {
+		return 0, fmt.Errorf("invalid percentage %q: %q is not a number", s, num)
+	}
+	return Percent(f), nil
+}
+
+// Fraction returns the percentage as a fraction, 0.85 for 85%
+func (p Percent) Fraction() float64 {
+	return float64(p) / 100
+}
+
+// Of returns the percentage of v, Percent(10).Of(200) is 20
+func (p Percent) Of(v float64) float64 {
+	return v * p.Fraction()
+}
+
+func (p Percent) String() string {
+	return strconv.FormatFloat(float64(p), 'f', -1, 64) + "%"
+}
+
+func (p Percent) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Percent) UnmarshalText(text []byte) error {
+	v, err := ParsePercent(string(text))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// Rate is a number of events per period, written "100/s", "5000/min" or "10/30s".
+type Rate struct {
+	Count float64
+	Per   time.Duration
+}
+
+// Units of Rate, the first name of each period is the one String uses
+var rateUnits = []struct {
+	names []string
+	per   time.Duration
+}{
+	{[]string{"ms", "millisecond"}, time.Millisecond},
+	{[]string{"s", "sec", "second"}, time.Second},
+	{[]string{"min", "m", "minute"}, time.Minute},
+	{[]string{"h", "hour"}, time.Hour},
+	{[]string{"d", "day"}, 24 * time.Hour},
+}
+
+// ParseRate parses a rate such as "100/s" or "5000/min". The period is a unit (ms, s, min, h, d)
+// or a Go duration, "10/30s".
+func ParseRate(s string) (Rate, error) {
+	count, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q: expected a count and a period, e.g. 100/s", s)
+	}
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0) || strings.TrimSpace(count) != count {
+		return Rate{}, fmt.Errorf("invalid rate %q: %q is not a positive number", s, count)
+	}
+	for _, u := range rateUnits {
+		for _, name := range u.names {
+			if name == period {
+				return Rate{Count: n, Per: u.per}, nil
+			}
+		}
+	}
+	per, err := time.ParseDuration(period)
+	if err != nil || per <= 0 || !strings.ContainsAny(period[:1], "0123456789") {
+		return Rate{}, fmt.Errorf("invalid rate %q: unknown period %q, expected ms, s, min, h, d or a duration", s, period)
+	}
+	return Rate{Count: n, Per: per}, nil
+}
+
+// PerSecond returns the number of events per second
+func (r Rate) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return r.Count / r.Per.Seconds()
+}
+
+// Interval returns the time between two events, 0 when the count is 0
+func (r Rate) Interval() time.Duration {
+	if r.Count <= 0 {
+		return 0
+	}
+	return time.Duration(float64(r.Per) / r.Count)
+}
+
+func (r Rate) String() string {
+	count := strconv.FormatFloat(r.Count, 'f', -1, 64)
+	for _, u := range rateUnits {
+		if u.per == r.Per {
+			return count + "/" + u.names[0]
+		}
+	}
+	return count + "/" + r.Per.String()
+}
+
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalText(text []byte) error {
+	v, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// splitNumber splits "1.5GB" into "1.5" and "GB"
+func splitNumber(s string) (string, string) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// textTypes are the value types read from their text with UnmarshalText. Other types implementing
+// encoding.TextUnmarshaler, such as slog.Level, keep being read by their kind, an int from an IntFlag.
+var textTypes = map[reflect.Type]bool{
+	reflect.TypeOf(ByteSize(0)):      true,
+	reflect.TypeOf(Percent(0)):       true,
+	reflect.TypeOf(Rate{}):           true,
+	reflect.TypeOf(HostPort{}):       true,
+	reflect.TypeOf(netip.Addr{}):     true,
+	reflect.TypeOf(netip.AddrPort{}): true,
+	reflect.TypeOf(netip.Prefix{}):   true,
+}
+
+// isTextType reports whether fields of type t are read from their text, t being ByteSize, Percent, Rate, HostPort,
+// a netip address or prefix, *url.URL, *regexp.Regexp or *template.Template
+func isTextType(t reflect.Type) bool {
+	return textTypes[t] || isPointerText(t)
+}
+
+// isNumber reports whether t is an integer or float kind
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseTextValue converts text to a value of the text type t
+func parseTextValue(t reflect.Type, s string) (reflect.Value, error) {
+	switch t {
+	case urlType:
+		u, err := url.Parse(s)
+		return reflect.ValueOf(u), err
+	case regexpType:
+		re, err := regexp.Compile(s)
+		return reflect.ValueOf(re), err
+	case templateType:
+		tmpl, err := parseTemplate(s)
+		return reflect.ValueOf(tmpl), err
+	}
+	p := reflect.New(t)
+	if err := p.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+		return p.Elem(), err
+	}
+	return p.Elem(), nil
+}
+
+// formatText returns the text form of a value, the inverse of parseText
+func formatText(v reflect.Value) (string, error) {
+	if isPointerText(v.Type()) && v.IsNil() {
+		return "", nil
+	}
+	switch x := v.Interface().(type) {
+	case *url.URL:
+		return x.String(), nil
+	case *regexp.Regexp:
+		return x.String(), nil
+	case *template.Template:
+		return formatTemplate(x), nil
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			return time.Duration(v.Int()).String(), nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "", nil
+		}
+		return formatText(v.Elem())
+	}
+	return "", errors.New("unsupported type " + v.Type().String())
+}
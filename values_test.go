@@ -0,0 +1,232 @@
+package clix
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cliv3 "github.com/urfave/cli/v3"
+)
+
+type Limits struct {
+	MaxBody   ByteSize   `cli:"max-body" cli-default:"10MiB" cli-max:"1GiB"`
+	Disk      ByteSize   `cli:"disk"`
+	Threshold Percent    `cli:"threshold" cli-default:"85%" cli-min:"0%" cli-max:"100%"`
+	Rate      Rate       `cli:"rate" cli-default:"100/s"`
+	Bursts    []Rate     `cli:"bursts"`
+	Chunks    []ByteSize `cli:"chunks" cli-default:"4KiB,1MiB"`
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+		str  string
+	}{
+		{"0", 0, "0B"},
+		{"512", 512, "512B"},
+		{"1024B", 1024, "1KiB"},
+		{"10MiB", 10 << 20, "10MiB"},
+		{"1.5GB", 1500000000, "1.5GB"},
+		{"1.5KiB", 1536, "1536B"},
+		{"1.25MiB", 1310720, "1.25MiB"},
+		{"1kB", 1000, "1KB"},
+		{"2TiB", 2 << 40, "2TiB"},
+		{"1001", 1001, "1001B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseByteSize(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.str, got.String())
+			back, err := ParseByteSize(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, back)
+		})
+	}
+}
+
+func TestParseByteSizeErrors(t *testing.T) {
+	tests := map[string]string{
+		"":       `invalid byte size "": expected a number and a unit, e.g. 10MiB`,
+		"10XB":   `invalid byte size "10XB": unknown unit "XB", expected B, KB, MB, GB, TB, PB, EB or KiB, MiB, GiB, TiB, PiB, EiB`,
+		"10 MiB": `invalid byte size "10 MiB": unknown unit " MiB", expected B, KB, MB, GB, TB, PB, EB or KiB, MiB, GiB, TiB, PiB, EiB`,
+		"10mb":   `invalid byte size "10mb": unknown unit "mb", expected B, KB, MB, GB, TB, PB, EB or KiB, MiB, GiB, TiB, PiB, EiB`,
+		"-1MB":   `invalid byte size "-1MB": expected a number and a unit, e.g. 10MiB`,
+		"1.5B":   `invalid byte size "1.5B": not a whole number of bytes`,
+		"9EiB":   `invalid byte size "9EiB": out of range`,
+		"MiB":    `invalid byte size "MiB": expected a number and a unit, e.g. 10MiB`,
+	}
+	for in, msg := range tests {
+		_, err := ParseByteSize(in)
+		assert.EqualError(t, err, msg, in)
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	p, err := ParsePercent("12.5%")
+	require.NoError(t, err)
+	assert.Equal(t, Percent(12.5), p)
+	assert.Equal(t, "12.5%", p.String())
+	assert.Equal(t, 0.125, p.Fraction())
+	assert.Equal(t, 25.0, p.Of(200))
+
+	_, err = ParsePercent("85")
+	assert.EqualError(t, err, `invalid percentage "85": expected a number followed by %, e.g. 85%`)
+	_, err = ParsePercent("lots%")
+	assert.EqualError(t, err, `invalid percentage "lots%": "lots" is not a number`)
+	_, err = ParsePercent("NaN%")
+	assert.Error(t, err)
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rate
+		str  string
+	}{
+		{"100/s", Rate{100, time.Second}, "100/s"},
+		{"5000/min", Rate{5000, time.Minute}, "5000/min"},
+		{"5000/m", Rate{5000, time.Minute}, "5000/min"},
+		{"2/hour", Rate{2, time.Hour}, "2/h"},
+		{"10/30s", Rate{10, 30 * time.Second}, "10/30s"},
+		{"0.5/d", Rate{0.5, 24 * time.Hour}, "0.5/d"},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got)
+		assert.Equal(t, tt.str, got.String())
+	}
+
+	r := Rate{Count: 5000, Per: time.Minute}
+	assert.InDelta(t, 83.33, r.PerSecond(), 0.01)
+	assert.Equal(t, 12*time.Millisecond, r.Interval())
+
+	_, err := ParseRate("100")
+	assert.EqualError(t, err, `invalid rate "100": expected a count and a period, e.g. 100/s`)
+	_, err = ParseRate("-1/s")
+	assert.EqualError(t, err, `invalid rate "-1/s": "-1" is not a positive number`)
+	_, err = ParseRate("100/fortnight")
+	assert.EqualError(t, err, `invalid rate "100/fortnight": unknown period "fortnight", expected ms, s, min, h, d or a duration`)
+	_, err = ParseRate("100/")
+	assert.Error(t, err)
+}
+
+func TestParseValueTypes(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+max-body: 512MiB
+disk: 1048576
+threshold: 90%
+bursts: [10/s, 500/min]
+`))
+	require.NoError(t, err)
+
+	cfg, err := TryParse[Limits](r)
+	require.NoError(t, err)
+	assert.Equal(t, Limits{
+		MaxBody:   512 << 20,
+		Disk:      1 << 20,
+		Threshold: 90,
+		Bursts:    []Rate{{10, time.Second}, {500, time.Minute}},
+	}, cfg)
+
+	ctx := newMockContext()
+	ctx.stringMap["max-body"] = "2MB"
+	ctx.stringSliceMap["chunks"] = []string{"1KiB"}
+	var limits Limits
+	AssignValueToCliFields(&limits, "", ctx)
+	assert.Equal(t, ByteSize(2e6), limits.MaxBody)
+	assert.Equal(t, []ByteSize{1 << 10}, limits.Chunks)
+}
+
+func TestParseValueTypesErrors(t *testing.T) {
+	r, err := NewFileReader([]byte(`
+max-body: 2GiB
+threshold: 85
+rate: fast
+bursts: [10/s, often]
+`))
+	require.NoError(t, err)
+
+	_, err = TryParse[Limits](r)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		`--threshold: invalid percentage "85": expected a number followed by %, e.g. 85%`,
+		`--rate: invalid rate "fast": expected a count and a period, e.g. 100/s`,
+		`--bursts: invalid rate "often": expected a count and a period, e.g. 100/s`,
+		"--max-body: 2GiB is greater than 1GiB",
+	}, messages)
+}
+
+func TestCompileValueTypesErrors(t *testing.T) {
+	type Config struct {
+		Size  ByteSize `cli:"size" cli-default:"lots"`
+		Rate  Rate     `cli:"rate" cli-max:"10/s"`
+		Share Percent  `cli:"share" cli-max:"half"`
+	}
+	_, err := Compile[Config]()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	var messages []string
+	for _, fe := range pe.Errors {
+		messages = append(messages, fe.Error())
+	}
+	assert.Equal(t, []string{
+		`--size: invalid cli-default "lots": invalid byte size "lots": expected a number and a unit, e.g. 10MiB`,
+		`--rate: invalid bound "10/s": clix.Rate has no order`,
+		`--share: invalid bound "half" for clix.Percent: invalid percentage "half": expected a number followed by %, e.g. 85%`,
+	}, messages)
+}
+
+func TestParseOtherTextUnmarshalers(t *testing.T) {
+	// slog.Level implements encoding.TextUnmarshaler, it is still read as the int it is
+	type Config struct {
+		Level slog.Level `cli:"level"`
+	}
+	defs, err := FlagDefs[Config]()
+	require.NoError(t, err)
+	assert.Equal(t, FlagDef{Name: "level", Type: reflect.TypeOf(0)}, defs[0])
+
+	var cfg Config
+	cmd := &cliv3.Command{
+		Flags: []cliv3.Flag{&cliv3.IntFlag{Name: "level"}},
+		Action: func(_ context.Context, cmd *cliv3.Command) error {
+			cfg, err = TryParseCommand[Config](cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"app", "--level", "4"}))
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, cfg.Level)
+}
+
+func TestDescribeValueTypes(t *testing.T) {
+	spec := Describe[Limits]()
+	require.Len(t, spec.Flags, 6)
+	assert.Equal(t, "bytesize", spec.Flags[0].Type)
+	assert.Equal(t, "percent", spec.Flags[2].Type)
+	assert.Equal(t, "rate", spec.Flags[3].Type)
+	assert.Equal(t, "[]rate", spec.Flags[4].Type)
+	assert.Empty(t, spec.Sections)
+
+	schema, err := JSONSchema[Limits]()
+	require.NoError(t, err)
+	assert.Equal(t, "string", schema.Properties["max-body"].Type)
+	assert.Equal(t, "10MiB", schema.Properties["max-body"].Default)
+
+	assert.Equal(t, []Change{
+		{Flag: "max-body", Field: "MaxBody", Old: ByteSize(10 << 20), New: ByteSize(1 << 30)},
+	}, Diff(Defaults[Limits](), Limits{MaxBody: 1 << 30, Threshold: 85, Rate: Rate{100, time.Second}, Chunks: []ByteSize{4 << 10, 1 << 20}}))
+}
@@ -0,0 +1,246 @@
+package clix
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WatchOptions configures Watch
+type WatchOptions struct {
+	// Interval is how often the file is checked when polling, 1s if zero
+	Interval time.Duration
+	// Poll forces polling even where file system notifications (inotify on Linux) are available
+	Poll bool
+	// Signals trigger a reload when received, SIGHUP on unix if nil. Use an empty, non-nil, slice to disable.
+	Signals []os.Signal
+	// Debounce is how long to wait for further changes before reloading, 50ms if zero
+	Debounce time.Duration
+	// Parse are the options the file is parsed with
+	Parse []Option
+}
+
+// Watcher holds the current value of a config file that is reloaded when the file changes.
+// It is created by Watch and stops when the context passed to Watch is done.
+type Watcher[T any] struct {
+	path string
+	opts WatchOptions
+
+	current atomic.Pointer[T]
+	errs    chan error
+	trigger chan struct{}
+	done    chan struct{}
+
+	mu     sync.Mutex // guards subs
+	subs   []subscriber[T]
+	nextID int
+}
+
+type subscriber[T any] struct {
+	id int
+	fn func(old, new T)
+}
+
+// Watch parses T from the file of r, with TryParse, and re-parses it whenever the file changes or, on unix, a SIGHUP is received.
+// A reloaded value replaces the current one only when it parses and validates, subscribers are then called with the old and new value.
+// Failed reloads are reported on Errors and leave the current value in place.
+// Watch itself fails when the initial value is invalid.
+// Usage:
+//
+//	r, err := clix.ReadFile("config.yaml")
+//	w, err := clix.Watch[Config](ctx, r, clix.WatchOptions{})
+//	w.Subscribe(func(old, new Config) { log.Printf("config reloaded") })
+//	cfg := w.Current()
+func Watch[T any](ctx context.Context, r *FileReader, opts WatchOptions) (*Watcher[T], error) {
+	if r.Path() == "" {
+		return nil, errors.New("clix: Watch needs a reader created by ReadFile")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = 50 * time.Millisecond
+	}
+	if opts.Signals == nil {
+		opts.Signals = defaultReloadSignals
+	}
+
+	cfg, err := parseFile[T](r, opts.Parse)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher[T]{
+		path:    r.Path(),
+		opts:    opts,
+		errs:    make(chan error, 8),
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	w.current.Store(&cfg)
+
+	w.start(ctx)
+	return w, nil
+}
+
+// parseFile parses T from r, failing on values that do not convert as well as on validation errors
+func parseFile[T any](r *FileReader, opts []Option) (T, error) {
+	cfg, err := TryParse[T](r, opts...)
+	if err == nil {
+		err = r.Err()
+	}
+	return cfg, err
+}
+
+// Current returns the latest valid value. The value is shared and must not be modified.
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load()
+}
+
+// Subscribe registers fn to be called with the old and new value after each successful reload that changed the value.
+// Subscribers are called one at a time, in the order they subscribed, from the goroutine of the watcher. The returned func removes the subscription.
+func (w *Watcher[T]) Subscribe(fn func(old, new T)) (cancel func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextID
+	w.nextID++
+	w.subs = append(w.subs, subscriber[T]{id: id, fn: fn})
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		for i, s := range w.subs {
+			if s.id == id {
+				w.subs = append(w.subs[:i:i], w.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Errors reports reloads that failed. Errors are dropped when the channel is not read, it is closed when the watcher stops.
+func (w *Watcher[T]) Errors() <-chan error {
+	return w.errs
+}
+
+// Done is closed when the watcher has stopped
+func (w *Watcher[T]) Done() <-chan struct{} {
+	return w.done
+}
+
+// Reload re-reads the file now, as a change or a signal would
+func (w *Watcher[T]) Reload() {
+	select {
+	case w.trigger <- struct{}{}:
+	default: // a reload is already pending
+	}
+}
+
+// start sets up the file watch and the signal handler before returning, so no change made after Watch returns is missed,
+// and runs the reload loop in the background
+func (w *Watcher[T]) start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+	if w.opts.Poll || watchFile(ctx, w.path, notify) != nil {
+		pollFile(ctx, w.path, w.opts.Interval, notify)
+	}
+
+	signals := make(chan os.Signal, 1)
+	if len(w.opts.Signals) > 0 {
+		signal.Notify(signals, w.opts.Signals...)
+	}
+
+	go func() {
+		defer close(w.done)
+		defer close(w.errs)
+		defer signal.Stop(signals)
+		defer cancel()
+		w.run(ctx, changes, signals)
+	}()
+}
+
+func (w *Watcher[T]) run(ctx context.Context, changes <-chan struct{}, signals <-chan os.Signal) {
+	debounce := time.NewTimer(0)
+	<-debounce.C
+	for {
+		select {
+		case <-ctx.Done():
+			debounce.Stop()
+			return
+		case <-changes:
+			debounce.Reset(w.opts.Debounce)
+		case <-signals:
+			w.reload()
+		case <-w.trigger:
+			w.reload()
+		case <-debounce.C:
+			w.reload()
+		}
+	}
+}
+
+// reload is only called from run, so reloads never overlap
+func (w *Watcher[T]) reload() {
+	r, err := ReadFile(w.path)
+	var cfg T
+	if err == nil {
+		cfg, err = parseFile[T](r, w.opts.Parse)
+	}
+	if err != nil {
+		select {
+		case w.errs <- err:
+		default:
+		}
+		return
+	}
+
+	old := w.current.Swap(&cfg)
+	if reflect.DeepEqual(*old, cfg) {
+		return
+	}
+	w.mu.Lock()
+	subs := w.subs
+	w.mu.Unlock()
+	for _, s := range subs {
+		s.fn(*old, cfg)
+	}
+}
+
+// pollFile calls notify, from a new goroutine, whenever the modification time or size of the file changes
+func pollFile(ctx context.Context, path string, interval time.Duration, notify func()) {
+	stat := func() (time.Time, int64) {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return fi.ModTime(), fi.Size()
+	}
+
+	mod, size := stat()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if m, s := stat(); !m.Equal(mod) || s != size {
+					mod, size = m, s
+					notify()
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package clix
+
+import "os"
+
+// defaultReloadSignals is empty where there is no SIGHUP, Watch then only reloads on file changes
+var defaultReloadSignals = []os.Signal{}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package clix
+
+import (
+	"context"
+	"errors"
+)
+
+// watchFile is only implemented on Linux, elsewhere Watch polls
+func watchFile(context.Context, string, func()) error {
+	return errors.New("file notifications are not supported on this platform")
+}
@@ -0,0 +1,115 @@
+package clix
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type WatchConfig struct {
+	Host string `cli:"host" cli-required:"true"`
+	Port int    `cli:"port" cli-max:"65535"`
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func startWatch(t *testing.T, content string, opts WatchOptions) (*Watcher[WatchConfig], string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, content)
+
+	r, err := ReadFile(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	w, err := Watch[WatchConfig](ctx, r, opts)
+	require.NoError(t, err)
+	return w, path
+}
+
+func TestWatchReloadsOnChange(t *testing.T) {
+	for _, poll := range []bool{false, true} {
+		t.Run(map[bool]string{false: "notify", true: "poll"}[poll], func(t *testing.T) {
+			w, path := startWatch(t, "host: a\nport: 1\n", WatchOptions{Poll: poll, Interval: 10 * time.Millisecond, Debounce: 5 * time.Millisecond})
+			assert.Equal(t, WatchConfig{Host: "a", Port: 1}, *w.Current())
+
+			changes := make(chan [2]WatchConfig, 1)
+			w.Subscribe(func(old, new WatchConfig) { changes <- [2]WatchConfig{old, new} })
+
+			writeConfig(t, path, "host: b\nport: 2\n")
+			select {
+			case c := <-changes:
+				assert.Equal(t, WatchConfig{Host: "a", Port: 1}, c[0])
+				assert.Equal(t, WatchConfig{Host: "b", Port: 2}, c[1])
+			case <-time.After(5 * time.Second):
+				t.Fatal("no reload")
+			}
+			assert.Equal(t, WatchConfig{Host: "b", Port: 2}, *w.Current())
+		})
+	}
+}
+
+func TestWatchKeepsValueOnInvalidReload(t *testing.T) {
+	w, path := startWatch(t, "host: a\n", WatchOptions{Signals: []os.Signal{}})
+
+	called := false
+	w.Subscribe(func(old, new WatchConfig) { called = true })
+
+	writeConfig(t, path, "port: 70000\n")
+	w.Reload()
+	select {
+	case err := <-w.Errors():
+		var pe *ParseError
+		require.True(t, errors.As(err, &pe))
+		assert.Len(t, pe.Errors, 2)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no error")
+	}
+	assert.Equal(t, WatchConfig{Host: "a"}, *w.Current())
+	assert.False(t, called)
+}
+
+func TestWatchUnsubscribeAndStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "host: a\n")
+	r, err := ReadFile(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := Watch[WatchConfig](ctx, r, WatchOptions{Signals: []os.Signal{}})
+	require.NoError(t, err)
+
+	cancelSub := w.Subscribe(func(old, new WatchConfig) { t.Error("unsubscribed func called") })
+	cancelSub()
+
+	writeConfig(t, path, "host: b\n")
+	w.Reload()
+	cancel()
+	<-w.Done()
+	_, open := <-w.Errors()
+	assert.False(t, open)
+}
+
+func TestWatchErrors(t *testing.T) {
+	r, err := NewFileReader([]byte("host: a\n"))
+	require.NoError(t, err)
+	_, err = Watch[WatchConfig](context.Background(), r, WatchOptions{})
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "port: 1\n")
+	r, err = ReadFile(path)
+	require.NoError(t, err)
+	_, err = Watch[WatchConfig](context.Background(), r, WatchOptions{})
+	assert.ErrorContains(t, err, "--host: required flag is not set")
+}
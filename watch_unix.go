@@ -0,0 +1,11 @@
+//go:build unix
+
+package clix
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReloadSignals are the signals Watch reloads on when WatchOptions.Signals is nil
+var defaultReloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package clix
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchSIGHUP(t *testing.T) {
+	w, path := startWatch(t, "host: a\n", WatchOptions{Poll: true, Interval: time.Hour})
+
+	changed := make(chan WatchConfig, 1)
+	w.Subscribe(func(_, new WatchConfig) { changed <- new })
+
+	writeConfig(t, path, "host: b\n")
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	select {
+	case cfg := <-changed:
+		assert.Equal(t, "b", cfg.Host)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload")
+	}
+}